@@ -0,0 +1,487 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultBenchSet        = "bench/dataset.json"
+	defaultBenchResultsDir = "bench/results"
+)
+
+// benchCase is one labelled example: a job, optionally its own resume, the
+// score range a good evaluation lands in and the gaps it should name.
+type benchCase struct {
+	ID              string         `json:"id"`
+	Resume          string         `json:"resume,omitempty"`
+	ResumeFile      string         `json:"resume_file,omitempty"`
+	Job             JobDescription `json:"job"`
+	ExpectedMin     int            `json:"expected_min"`
+	ExpectedMax     int            `json:"expected_max"`
+	ExpectedMissing []string       `json:"expected_missing,omitempty"`
+}
+
+type benchDataset struct {
+	Name       string      `json:"name"`
+	ResumeFile string      `json:"resume_file,omitempty"` // default resume for cases without their own
+	Cases      []benchCase `json:"cases"`
+}
+
+// loadBenchDataset reads the dataset and resolves every case's resume, with
+// relative resume paths taken from the dataset's directory.
+func loadBenchDataset(path string) (benchDataset, error) {
+	var ds benchDataset
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ds, fmt.Errorf("failed to read bench dataset: %w", err)
+	}
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return ds, fmt.Errorf("failed to decode bench dataset %s: %w", path, err)
+	}
+	if len(ds.Cases) == 0 {
+		return ds, fmt.Errorf("bench dataset %s has no cases", path)
+	}
+	if ds.Name == "" {
+		ds.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+	var errs []string
+	for i := range ds.Cases {
+		c := &ds.Cases[i]
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("case-%d", i+1)
+		}
+		if c.ExpectedMin < 0 || c.ExpectedMax > 100 || c.ExpectedMin > c.ExpectedMax {
+			errs = append(errs, fmt.Sprintf("%s: expected range %d-%d is invalid", c.ID, c.ExpectedMin, c.ExpectedMax))
+		}
+		if c.Resume != "" {
+			continue
+		}
+		file := resolve(c.ResumeFile)
+		if file == "" {
+			file = resolve(ds.ResumeFile)
+		}
+		if file == "" {
+			errs = append(errs, fmt.Sprintf("%s: no resume or resume_file, and the dataset has no default", c.ID))
+			continue
+		}
+		resume, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.ID, err))
+			continue
+		}
+		c.Resume = string(resume)
+	}
+	if len(errs) > 0 {
+		return ds, fmt.Errorf("bench dataset %s:\n  %s", path, strings.Join(errs, "\n  "))
+	}
+	return ds, nil
+}
+
+type benchCaseResult struct {
+	ID               string   `json:"id"`
+	Score            int      `json:"score"`
+	RawScore         int      `json:"raw_score"`
+	ExpectedMin      int      `json:"expected_min"`
+	ExpectedMax      int      `json:"expected_max"`
+	MissingExpected  []string `json:"missing_expected,omitempty"`
+	MissingFound     []string `json:"missing_found,omitempty"`
+	Samples          int      `json:"samples"`
+	FormatErrors     int      `json:"format_errors"`
+	LatencyMs        float64  `json:"latency_ms"` // per sample
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	Error            string   `json:"error,omitempty"`
+}
+
+type benchMetrics struct {
+	Cases            int     `json:"cases"`
+	Failed           int     `json:"failed"`
+	MAE              float64 `json:"mae"`      // against the middle of each expected range
+	InRange          float64 `json:"in_range"` // share of cases scored inside the expected range
+	Spearman         float64 `json:"spearman"` // rank correlation of scores with expected midpoints
+	FormatCompliance float64 `json:"format_compliance"`
+	MissingRecall    float64 `json:"missing_recall"` // share of expected gaps named under Missing Qualifications
+	MeanLatencyMs    float64 `json:"mean_latency_ms"`
+	P95LatencyMs     float64 `json:"p95_latency_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+}
+
+type benchRun struct {
+	Dataset       string            `json:"dataset"`
+	Model         string            `json:"model"`
+	PromptVersion string            `json:"prompt_version"`
+	Calibrated    bool              `json:"calibrated"`
+	Label         string            `json:"label,omitempty"`
+	StartedAt     time.Time         `json:"started_at"`
+	Metrics       benchMetrics      `json:"metrics"`
+	Cases         []benchCaseResult `json:"cases"`
+}
+
+var missingSectionPattern = regexp.MustCompile(`(?is)Missing Qualifications:(.*?)(?:\n\s*---|\z)`)
+
+// missingFound returns the expected gaps the evaluation named, looking in
+// its Missing Qualifications section when there is one.
+func missingFound(text string, expected []string) []string {
+	section := text
+	if m := missingSectionPattern.FindStringSubmatch(text); m != nil {
+		section = m[1]
+	}
+	section = strings.ToLower(section)
+	var found []string
+	for _, skill := range expected {
+		if strings.Contains(section, strings.ToLower(skill)) {
+			found = append(found, skill)
+		}
+	}
+	return found
+}
+
+func newBenchCaseResult(c benchCase, eval Evaluation) benchCaseResult {
+	r := benchCaseResult{
+		ID:               c.ID,
+		Score:            eval.Score,
+		RawScore:         eval.RawScore,
+		ExpectedMin:      c.ExpectedMin,
+		ExpectedMax:      c.ExpectedMax,
+		MissingExpected:  c.ExpectedMissing,
+		MissingFound:     missingFound(eval.Text, c.ExpectedMissing),
+		Samples:          eval.Samples,
+		FormatErrors:     eval.FormatErrors,
+		PromptTokens:     eval.PromptTokens,
+		CompletionTokens: eval.CompletionTokens,
+	}
+	if eval.Samples > 0 {
+		r.LatencyMs = float64(eval.Latency.Milliseconds()) / float64(eval.Samples)
+	}
+	return r
+}
+
+// computeBenchMetrics summarizes the case results. Failed cases count
+// against format compliance but not against the score metrics.
+func computeBenchMetrics(results []benchCaseResult) benchMetrics {
+	m := benchMetrics{Cases: len(results)}
+	var scores, targets, latencies []float64
+	var absErr float64
+	inRange, samples, formatErrors, expected, found := 0, 0, 0, 0, 0
+
+	for _, r := range results {
+		if r.Error != "" {
+			m.Failed++
+			samples++
+			formatErrors++
+			continue
+		}
+		mid := float64(r.ExpectedMin+r.ExpectedMax) / 2
+		scores = append(scores, float64(r.Score))
+		targets = append(targets, mid)
+		absErr += math.Abs(float64(r.Score) - mid)
+		if r.Score >= r.ExpectedMin && r.Score <= r.ExpectedMax {
+			inRange++
+		}
+		samples += r.Samples
+		formatErrors += r.FormatErrors
+		expected += len(r.MissingExpected)
+		found += len(r.MissingFound)
+		latencies = append(latencies, r.LatencyMs)
+		m.PromptTokens += r.PromptTokens
+		m.CompletionTokens += r.CompletionTokens
+	}
+
+	if n := len(scores); n > 0 {
+		m.MAE = absErr / float64(n)
+		m.InRange = float64(inRange) / float64(n)
+		m.Spearman = spearman(scores, targets)
+		m.MeanLatencyMs = mean(latencies)
+		m.P95LatencyMs = percentile(latencies, 0.95)
+	}
+	if samples > 0 {
+		m.FormatCompliance = 1 - float64(formatErrors)/float64(samples)
+	}
+	if expected > 0 {
+		m.MissingRecall = float64(found) / float64(expected)
+	}
+	return m
+}
+
+// spearman is the Pearson correlation of the ranks, with ties sharing the
+// average of their ranks. It's 0 when either side has no variation.
+func spearman(xs, ys []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	rx, ry := ranks(xs), ranks(ys)
+	mx, my := mean(rx), mean(ry)
+	var num, dx, dy float64
+	for i := range rx {
+		num += (rx[i] - mx) * (ry[i] - my)
+		dx += (rx[i] - mx) * (rx[i] - mx)
+		dy += (ry[i] - my) * (ry[i] - my)
+	}
+	if dx == 0 || dy == 0 {
+		return 0
+	}
+	return num / math.Sqrt(dx*dy)
+}
+
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	out := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		avg := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			out[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return out
+}
+
+// percentile uses the nearest-rank method.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// runBench evaluates every case with one model.
+func runBench(s evalSettings, ds benchDataset, model string) benchRun {
+	s.sampling.Models = []string{model}
+	run := benchRun{
+		Dataset:       ds.Name,
+		Model:         model,
+		PromptVersion: s.evalPrompt.ID(),
+		Calibrated:    s.calibrations.lookup(model, s.evalPrompt.ID()) != nil,
+		StartedAt:     time.Now(),
+	}
+
+	for i, c := range ds.Cases {
+		cs := s
+		cs.resume = c.Resume
+		cs.resumeSkills = findSkills(c.Resume)
+		logStage(stageLLM).Info("Running bench case", "model", model, "case", c.ID, "n", i+1, "of", len(ds.Cases))
+
+		eval, err := evaluateJob(context.Background(), cs, i, c.Job)
+		if err != nil {
+			run.Cases = append(run.Cases, benchCaseResult{ID: c.ID, ExpectedMin: c.ExpectedMin, ExpectedMax: c.ExpectedMax, MissingExpected: c.ExpectedMissing, Error: err.Error()})
+			continue
+		}
+		run.Cases = append(run.Cases, newBenchCaseResult(c, eval))
+	}
+	run.Metrics = computeBenchMetrics(run.Cases)
+	return run
+}
+
+func saveBenchRun(dir string, run benchRun) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	safe := regexp.MustCompile(`[^A-Za-z0-9._-]+`).ReplaceAllString(run.Model, "_")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-%s.json", run.StartedAt.Format("20060102-150405"), run.Dataset, safe))
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+func loadBenchRun(path string) (benchRun, error) {
+	var run benchRun
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return run, err
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("failed to decode bench run %s: %w", path, err)
+	}
+	return run, nil
+}
+
+// latestBenchRun finds the newest saved run on the same dataset and model,
+// so a prompt change is compared like for like by default.
+func latestBenchRun(dir, dataset, model string) (benchRun, string, bool) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, f := range files {
+		run, err := loadBenchRun(f)
+		if err == nil && run.Dataset == dataset && run.Model == model {
+			return run, f, true
+		}
+	}
+	return benchRun{}, "", false
+}
+
+type benchMetricRow struct {
+	Name           string
+	Baseline       float64
+	Current        float64
+	HigherIsBetter bool
+	Format         string
+}
+
+func benchMetricRows(base, cur benchMetrics) []benchMetricRow {
+	return []benchMetricRow{
+		{"MAE", base.MAE, cur.MAE, false, "%.1f"},
+		{"in range", base.InRange * 100, cur.InRange * 100, true, "%.0f%%"},
+		{"spearman", base.Spearman, cur.Spearman, true, "%.3f"},
+		{"format compliance", base.FormatCompliance * 100, cur.FormatCompliance * 100, true, "%.0f%%"},
+		{"missing recall", base.MissingRecall * 100, cur.MissingRecall * 100, true, "%.0f%%"},
+		{"mean latency ms", base.MeanLatencyMs, cur.MeanLatencyMs, false, "%.0f"},
+		{"p95 latency ms", base.P95LatencyMs, cur.P95LatencyMs, false, "%.0f"},
+		{"prompt tokens", float64(base.PromptTokens), float64(cur.PromptTokens), false, "%.0f"},
+		{"completion tokens", float64(base.CompletionTokens), float64(cur.CompletionTokens), false, "%.0f"},
+		{"failed cases", float64(base.Failed), float64(cur.Failed), false, "%.0f"},
+	}
+}
+
+// printBenchRun prints the metrics, with a baseline column and a verdict
+// per metric when a previous run is given.
+func printBenchRun(run benchRun, baseline *benchRun) error {
+	fmt.Printf("\n%s on %s (%s, %d cases)\n", run.Model, run.Dataset, run.PromptVersion, run.Metrics.Cases)
+	if baseline != nil {
+		fmt.Printf("Baseline: %s (%s, %s)\n", baseline.Model, baseline.PromptVersion, baseline.StartedAt.Format("2006-01-02 15:04"))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if baseline == nil {
+		fmt.Fprintln(w, "METRIC\tVALUE")
+		for _, r := range benchMetricRows(run.Metrics, run.Metrics) {
+			fmt.Fprintf(w, "%s\t"+r.Format+"\n", r.Name, r.Current)
+		}
+		return w.Flush()
+	}
+
+	fmt.Fprintln(w, "METRIC\tBASELINE\tCURRENT\tDELTA\t")
+	for _, r := range benchMetricRows(baseline.Metrics, run.Metrics) {
+		delta := r.Current - r.Baseline
+		verdict := ""
+		if delta != 0 && (delta > 0) == r.HigherIsBetter {
+			verdict = "better"
+		} else if delta != 0 {
+			verdict = "worse"
+		}
+		fmt.Fprintf(w, "%s\t"+r.Format+"\t"+r.Format+"\t%+.3g\t%s\n", r.Name, r.Baseline, r.Current, delta, verdict)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Cases whose score moved noticeably are worth reading by hand.
+	previous := map[string]benchCaseResult{}
+	for _, c := range baseline.Cases {
+		previous[c.ID] = c
+	}
+	var moved []string
+	for _, c := range run.Cases {
+		if p, ok := previous[c.ID]; ok && p.Error == "" && c.Error == "" && abs(c.Score-p.Score) >= 10 {
+			moved = append(moved, fmt.Sprintf("  %s: %d → %d (expected %d-%d)", c.ID, p.Score, c.Score, c.ExpectedMin, c.ExpectedMax))
+		}
+	}
+	if len(moved) > 0 {
+		fmt.Println("\nCases that moved 10+ points:")
+		fmt.Println(strings.Join(moved, "\n"))
+	}
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// runBenchCommand runs the labelled dataset through each model and compares
+// the result with a previous run.
+func runBenchCommand(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	setPath := fs.String("set", defaultBenchSet, "labelled dataset")
+	models := fs.String("models", "", "comma-separated models to benchmark (default: the configured evaluation models)")
+	baselinePath := fs.String("baseline", "", "previous run to compare with (default: the latest run on the same dataset and model)")
+	outDir := fs.String("out", defaultBenchResultsDir, "directory for run results")
+	raw := fs.Bool("raw", false, "ignore stored calibrations")
+	label := fs.String("label", "", "note saved with the run, e.g. the change being tested")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ds, err := loadBenchDataset(*setPath)
+	if err != nil {
+		return err
+	}
+	settings, err := loadEvalSettings()
+	if err != nil {
+		return err
+	}
+	// Few-shot feedback changes as verdicts come in, which would make runs
+	// incomparable.
+	settings.examples = nil
+	if *raw {
+		settings.calibrations = nil
+	}
+
+	modelList := settings.sampling.Models
+	if *models != "" {
+		modelList = splitList(*models, false)
+	}
+	if len(modelList) == 0 {
+		return errors.New("no models to benchmark")
+	}
+
+	for _, model := range modelList {
+		var baseline *benchRun
+		if *baselinePath != "" {
+			b, err := loadBenchRun(*baselinePath)
+			if err != nil {
+				return err
+			}
+			baseline = &b
+		} else if b, _, ok := latestBenchRun(*outDir, ds.Name, model); ok {
+			baseline = &b
+		}
+
+		run := runBench(settings, ds, model)
+		run.Label = *label
+		path, err := saveBenchRun(*outDir, run)
+		if err != nil {
+			return err
+		}
+		if err := printBenchRun(run, baseline); err != nil {
+			return err
+		}
+		logStage(stageLLM).Info("Saved bench run", "file", path)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpearman(t *testing.T) {
+	if got := spearman([]float64{1, 2, 3, 4}, []float64{10, 20, 30, 40}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("monotonic = %v, want 1", got)
+	}
+	if got := spearman([]float64{4, 3, 2, 1}, []float64{10, 20, 30, 40}); math.Abs(got+1) > 1e-9 {
+		t.Errorf("reversed = %v, want -1", got)
+	}
+	if got := spearman([]float64{5, 5, 5}, []float64{1, 2, 3}); got != 0 {
+		t.Errorf("constant = %v, want 0", got)
+	}
+	if got := ranks([]float64{30, 10, 30, 20}); !reflect.DeepEqual(got, []float64{3.5, 1, 3.5, 2}) {
+		t.Errorf("ranks = %v", got)
+	}
+}
+
+func TestMissingFound(t *testing.T) {
+	text := "Fit Score: 60\n\nMatching Qualifications:\n- Go\n\nMissing Qualifications:\n- kubernetes\n- AWS certification\n"
+	got := missingFound(text, []string{"Kubernetes", "Go", "AWS"})
+	if !reflect.DeepEqual(got, []string{"Kubernetes", "AWS"}) {
+		t.Errorf("missingFound = %v", got)
+	}
+}
+
+func TestComputeBenchMetrics(t *testing.T) {
+	results := []benchCaseResult{
+		{ID: "a", Score: 80, ExpectedMin: 70, ExpectedMax: 90, Samples: 2, LatencyMs: 100, PromptTokens: 500, CompletionTokens: 100,
+			MissingExpected: []string{"Kubernetes", "AWS"}, MissingFound: []string{"AWS"}},
+		{ID: "b", Score: 50, ExpectedMin: 10, ExpectedMax: 30, Samples: 2, FormatErrors: 1, LatencyMs: 300, PromptTokens: 500, CompletionTokens: 100},
+		{ID: "c", Error: "connection refused", ExpectedMin: 0, ExpectedMax: 10},
+	}
+	m := computeBenchMetrics(results)
+	if m.Cases != 3 || m.Failed != 1 {
+		t.Errorf("cases/failed = %d/%d, want 3/1", m.Cases, m.Failed)
+	}
+	if m.MAE != 15 { // |80-80| and |50-20|
+		t.Errorf("MAE = %v, want 15", m.MAE)
+	}
+	if m.InRange != 0.5 || m.MissingRecall != 0.5 {
+		t.Errorf("in range = %v, recall = %v, want 0.5 each", m.InRange, m.MissingRecall)
+	}
+	if m.FormatCompliance != 0.6 { // 2 bad out of 5, counting the failed case as one
+		t.Errorf("format compliance = %v, want 0.6", m.FormatCompliance)
+	}
+	if m.MeanLatencyMs != 200 || m.P95LatencyMs != 300 || m.PromptTokens != 1000 {
+		t.Errorf("latency %v/%v, tokens %d", m.MeanLatencyMs, m.P95LatencyMs, m.PromptTokens)
+	}
+}
+
+func TestLoadBenchDataset(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "resume.txt"), []byte("Go developer"), 0644)
+	path := filepath.Join(dir, "set.json")
+	os.WriteFile(path, []byte(`{"resume_file": "resume.txt", "cases": [
+		{"job": {"job_id": "1"}, "expected_min": 10, "expected_max": 20},
+		{"id": "own", "resume": "Python", "job": {"job_id": "2"}, "expected_min": 0, "expected_max": 5}
+	]}`), 0644)
+
+	ds, err := loadBenchDataset(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Name != "set" || ds.Cases[0].ID != "case-1" || ds.Cases[0].Resume != "Go developer" || ds.Cases[1].Resume != "Python" {
+		t.Errorf("dataset = %+v", ds)
+	}
+
+	os.WriteFile(path, []byte(`{"cases": [{"job": {}, "expected_min": 50, "expected_max": 20}]}`), 0644)
+	if _, err := loadBenchDataset(path); err == nil {
+		t.Error("expected error for an inverted range and a missing resume")
+	}
+}
+
+func TestShippedBenchDataset(t *testing.T) {
+	ds, err := loadBenchDataset(defaultBenchSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range ds.Cases {
+		if c.Resume == "" || c.Job.JobID == "" || c.Job.JobDescription == "" {
+			t.Errorf("case %s needs a resume, a job ID and a description", c.ID)
+		}
+	}
+}
+
+func TestLatestBenchRun(t *testing.T) {
+	dir := t.TempDir()
+	older := benchRun{Dataset: "d", Model: "m", StartedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Metrics: benchMetrics{MAE: 20}}
+	newer := benchRun{Dataset: "d", Model: "m", StartedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Metrics: benchMetrics{MAE: 10}}
+	other := benchRun{Dataset: "d", Model: "x", StartedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	for _, r := range []benchRun{older, newer, other} {
+		if _, err := saveBenchRun(dir, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _, ok := latestBenchRun(dir, "d", "m")
+	if !ok || got.Metrics.MAE != 10 {
+		t.Errorf("latest = %+v, %v; want the February run", got, ok)
+	}
+	if _, _, ok := latestBenchRun(dir, "other", "m"); ok {
+		t.Error("found a run for an unknown dataset")
+	}
+}
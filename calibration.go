@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultCalibrationSet = "calibration/reference.json"
+
+	calibrationLinear   = "linear"
+	calibrationIsotonic = "isotonic"
+)
+
+// calibrationPoint is one knot of an isotonic mapping from raw to reference score.
+type calibrationPoint struct {
+	Raw    float64 `json:"raw"`
+	Target float64 `json:"target"`
+}
+
+// Calibration maps one model+prompt's raw scores onto the reference scale.
+type Calibration struct {
+	Model         string             `json:"model"`
+	PromptVersion string             `json:"prompt_version"`
+	Method        string             `json:"method"`
+	Intercept     float64            `json:"intercept,omitempty"`
+	Slope         float64            `json:"slope,omitempty"`
+	Points        []calibrationPoint `json:"points,omitempty"`
+	Samples       int                `json:"samples"`
+	MAEBefore     float64            `json:"mae_before"`
+	MAEAfter      float64            `json:"mae_after"`
+	FittedAt      time.Time          `json:"fitted_at"`
+}
+
+// apply maps a raw score to the reference scale.
+func (c *Calibration) apply(raw int) int {
+	x := float64(raw)
+	var y float64
+	switch c.Method {
+	case calibrationIsotonic:
+		y = interpolate(c.Points, x)
+	default:
+		y = c.Intercept + c.Slope*x
+	}
+	return clampScore(int(math.Round(y)))
+}
+
+// interpolate is piecewise linear between knots and flat beyond the ends.
+func interpolate(points []calibrationPoint, x float64) float64 {
+	if len(points) == 0 {
+		return x
+	}
+	if x <= points[0].Raw {
+		return points[0].Target
+	}
+	for i := 1; i < len(points); i++ {
+		if x <= points[i].Raw {
+			a, b := points[i-1], points[i]
+			return a.Target + (x-a.Raw)/(b.Raw-a.Raw)*(b.Target-a.Target)
+		}
+	}
+	return points[len(points)-1].Target
+}
+
+// calibrationSet holds the fitted mappings keyed by model and prompt ID.
+type calibrationSet map[string]*Calibration
+
+func calibrationKey(model, promptVersion string) string {
+	return model + "|" + promptVersion
+}
+
+// lookup returns the mapping for model and prompt, or nil. A prompt edit
+// changes its version, so stale calibrations stop applying on their own.
+func (cs calibrationSet) lookup(model, promptVersion string) *Calibration {
+	return cs[calibrationKey(model, promptVersion)]
+}
+
+func loadCalibrations(path string) (calibrationSet, error) {
+	cs := calibrationSet{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibrations %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return nil, fmt.Errorf("failed to decode calibrations %s: %w", path, err)
+	}
+	return cs, nil
+}
+
+func saveCalibrations(path string, cs calibrationSet) error {
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fitCalibration fits raw scores to targets with the given method.
+func fitCalibration(method string, raw, target []float64) (*Calibration, error) {
+	if len(raw) < 2 {
+		return nil, fmt.Errorf("need at least 2 scored reference jobs, got %d", len(raw))
+	}
+	c := &Calibration{Method: method, Samples: len(raw)}
+	switch method {
+	case calibrationLinear:
+		slope := linearSlope(raw, target)
+		if slope == 0 && stddev(raw) == 0 {
+			return nil, errors.New("every reference job got the same raw score; a linear fit is undefined")
+		}
+		c.Slope = slope
+		c.Intercept = mean(target) - slope*mean(raw)
+	case calibrationIsotonic:
+		c.Points = isotonicFit(raw, target)
+	default:
+		return nil, fmt.Errorf("unknown calibration method %q (available: linear, isotonic)", method)
+	}
+
+	var before, after float64
+	for i := range raw {
+		before += math.Abs(raw[i] - target[i])
+		after += math.Abs(float64(c.apply(int(raw[i]))) - target[i])
+	}
+	c.MAEBefore = before / float64(len(raw))
+	c.MAEAfter = after / float64(len(raw))
+	return c, nil
+}
+
+// isotonicFit runs pool-adjacent-violators to find the best non-decreasing
+// mapping, returning one knot per pooled block.
+func isotonicFit(raw, target []float64) []calibrationPoint {
+	idx := make([]int, len(raw))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return raw[idx[a]] < raw[idx[b]] })
+
+	type block struct{ sumX, sumY, n float64 }
+	var blocks []block
+	for _, i := range idx {
+		blocks = append(blocks, block{raw[i], target[i], 1})
+		for len(blocks) > 1 {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if prev.sumY/prev.n <= last.sumY/last.n {
+				break
+			}
+			blocks = blocks[:len(blocks)-2]
+			blocks = append(blocks, block{prev.sumX + last.sumX, prev.sumY + last.sumY, prev.n + last.n})
+		}
+	}
+
+	points := make([]calibrationPoint, 0, len(blocks))
+	for _, b := range blocks {
+		p := calibrationPoint{Raw: b.sumX / b.n, Target: b.sumY / b.n}
+		// Blocks with equal mean raw scores would make interpolation divide by zero.
+		if n := len(points); n > 0 && points[n-1].Raw == p.Raw {
+			points[n-1].Target = (points[n-1].Target + p.Target) / 2
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// referenceJob is a job with an agreed score in the calibration set.
+type referenceJob struct {
+	Job    JobDescription `json:"job"`
+	Target int            `json:"target"`
+	Note   string         `json:"note,omitempty"`
+}
+
+// referenceSet is the calibration set file. Target scores only make sense
+// for a fixed resume, so the set names its own.
+type referenceSet struct {
+	ResumeFile string         `json:"resume_file"`
+	Jobs       []referenceJob `json:"jobs"`
+}
+
+func loadReferenceSet(path string) (referenceSet, error) {
+	var set referenceSet
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return set, fmt.Errorf("failed to read calibration set: %w", err)
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return set, fmt.Errorf("failed to decode calibration set %s: %w", path, err)
+	}
+	if len(set.Jobs) < 2 {
+		return set, fmt.Errorf("calibration set %s needs at least 2 jobs", path)
+	}
+	for i, j := range set.Jobs {
+		if j.Target < 0 || j.Target > 100 {
+			return set, fmt.Errorf("calibration set %s: job %d target %d is outside 0-100", path, i+1, j.Target)
+		}
+	}
+	if set.ResumeFile != "" && !filepath.IsAbs(set.ResumeFile) {
+		set.ResumeFile = filepath.Join(filepath.Dir(path), set.ResumeFile)
+	}
+	return set, nil
+}
+
+// runCalibrateCommand scores the reference set with each model and fits a
+// mapping from its raw scores to the agreed targets.
+func runCalibrateCommand(args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	setPath := fs.String("set", defaultCalibrationSet, "calibration set of reference jobs with target scores")
+	method := fs.String("method", calibrationLinear, "mapping to fit: linear or isotonic")
+	models := fs.String("models", "", "comma-separated models to calibrate (default: the configured evaluation models)")
+	dryRun := fs.Bool("dry-run", false, "print the fit without saving it")
+	list := fs.Bool("list", false, "show the stored calibrations and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := config.Eval.CalibrationFile
+	stored, err := loadCalibrations(path)
+	if err != nil {
+		return err
+	}
+	if *list {
+		return printCalibrations(stored)
+	}
+	if *method != calibrationLinear && *method != calibrationIsotonic {
+		return fmt.Errorf("-method must be linear or isotonic, got %q", *method)
+	}
+
+	set, err := loadReferenceSet(*setPath)
+	if err != nil {
+		return err
+	}
+	settings, err := loadEvalSettings()
+	if err != nil {
+		return err
+	}
+	if set.ResumeFile != "" {
+		resume, err := os.ReadFile(set.ResumeFile)
+		if err != nil {
+			return err
+		}
+		settings.resume = string(resume)
+		settings.resumeSkills = findSkills(settings.resume)
+	}
+	// Calibrate raw output: no existing mapping, no few-shot feedback.
+	settings.calibrations = nil
+	settings.examples = nil
+
+	modelList := settings.sampling.Models
+	if *models != "" {
+		modelList = splitList(*models, false)
+	}
+
+	for _, model := range modelList {
+		s := settings
+		s.sampling.Models = []string{model}
+
+		var raw, target []float64
+		for i, ref := range set.Jobs {
+			eval, err := evaluateJob(context.Background(), s, i, ref.Job)
+			if err != nil {
+				logStage(stageLLM).Warn("Reference job failed", "model", model, "job", i+1, "title", ref.Job.JobPosition, "err", err)
+				continue
+			}
+			logStage(stageLLM).Info("Scored reference job", "model", model, "title", ref.Job.JobPosition, "raw_score", eval.RawScore, "target", ref.Target)
+			raw = append(raw, float64(eval.RawScore))
+			target = append(target, float64(ref.Target))
+		}
+
+		c, err := fitCalibration(*method, raw, target)
+		if err != nil {
+			return fmt.Errorf("calibrating %s: %w", model, err)
+		}
+		c.Model = model
+		c.PromptVersion = s.evalPrompt.ID()
+		c.FittedAt = time.Now()
+		stored[calibrationKey(c.Model, c.PromptVersion)] = c
+		logStage(stageLLM).Info("Fitted calibration", "model", model, "prompt", c.PromptVersion, "method", c.Method,
+			"mae_before", c.MAEBefore, "mae_after", c.MAEAfter, "jobs", c.Samples)
+	}
+
+	if *dryRun {
+		return nil
+	}
+	if err := saveCalibrations(path, stored); err != nil {
+		return err
+	}
+	logStage(stageLLM).Info("Saved calibrations", "file", path)
+	return nil
+}
+
+func printCalibrations(cs calibrationSet) error {
+	keys := make([]string, 0, len(cs))
+	for k := range cs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tPROMPT\tMETHOD\tMAPPING\tMAE BEFORE\tMAE AFTER\tJOBS\tFITTED")
+	for _, k := range keys {
+		c := cs[k]
+		mapping := fmt.Sprintf("%.2f×raw %+.1f", c.Slope, c.Intercept)
+		if c.Method == calibrationIsotonic {
+			var knots []string
+			for _, p := range c.Points {
+				knots = append(knots, fmt.Sprintf("%.0f→%.0f", p.Raw, p.Target))
+			}
+			mapping = strings.Join(knots, " ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\t%.1f\t%d\t%s\n", c.Model, c.PromptVersion, c.Method, mapping, c.MAEBefore, c.MAEAfter, c.Samples, c.FittedAt.Format("2006-01-02"))
+	}
+	return w.Flush()
+}
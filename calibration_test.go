@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFitCalibrationLinear(t *testing.T) {
+	// A small model that scores too high and squeezes everything into 60-90.
+	raw := []float64{60, 70, 80, 90}
+	target := []float64{20, 40, 60, 80}
+	c, err := fitCalibration(calibrationLinear, raw, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(c.Slope-2) > 1e-9 || math.Abs(c.Intercept+100) > 1e-9 {
+		t.Errorf("fit = %.2f×raw %+.2f, want 2×raw -100", c.Slope, c.Intercept)
+	}
+	if c.apply(75) != 50 || c.apply(40) != 0 || c.apply(99) != 98 {
+		t.Errorf("apply gave %d, %d, %d", c.apply(75), c.apply(40), c.apply(99))
+	}
+	if c.MAEBefore != 25 || c.MAEAfter != 0 {
+		t.Errorf("MAE before/after = %v/%v, want 25/0", c.MAEBefore, c.MAEAfter)
+	}
+
+	if _, err := fitCalibration(calibrationLinear, []float64{50, 50}, []float64{10, 90}); err == nil {
+		t.Error("expected error when every raw score is the same")
+	}
+	if _, err := fitCalibration("cubic", raw, target); err == nil {
+		t.Error("expected error for unknown method")
+	}
+}
+
+func TestFitCalibrationIsotonic(t *testing.T) {
+	raw := []float64{10, 20, 30, 40, 50}
+	target := []float64{5, 30, 20, 60, 90}
+	c, err := fitCalibration(calibrationIsotonic, raw, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 and 30 violate monotonicity and are pooled into one knot at 25 → 25.
+	want := []calibrationPoint{{10, 5}, {25, 25}, {40, 60}, {50, 90}}
+	if len(c.Points) != len(want) {
+		t.Fatalf("points = %v, want %v", c.Points, want)
+	}
+	for i := range want {
+		if c.Points[i] != want[i] {
+			t.Errorf("point %d = %v, want %v", i, c.Points[i], want[i])
+		}
+	}
+	if got := c.apply(0); got != 5 {
+		t.Errorf("below range = %d, want 5", got)
+	}
+	if got := c.apply(45); got != 75 {
+		t.Errorf("interpolated = %d, want 75", got)
+	}
+	if got := c.apply(100); got != 90 {
+		t.Errorf("above range = %d, want 90", got)
+	}
+}
+
+func TestCalibrationPersistenceAndLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cal", "calibration.json")
+	cs, err := loadCalibrations(path)
+	if err != nil || len(cs) != 0 {
+		t.Fatalf("missing file should load empty, got %v, %v", cs, err)
+	}
+	cs[calibrationKey("gemma3:1b", "evaluate@v3-abc")] = &Calibration{Model: "gemma3:1b", PromptVersion: "evaluate@v3-abc", Method: calibrationLinear, Slope: 1, Intercept: -10}
+	if err := saveCalibrations(path, cs); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadCalibrations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := loaded.lookup("gemma3:1b", "evaluate@v3-abc"); c == nil || c.apply(70) != 60 {
+		t.Errorf("lookup = %+v", c)
+	}
+	if loaded.lookup("gemma3:1b", "evaluate@v4-def") != nil {
+		t.Error("a new prompt version must not reuse an old calibration")
+	}
+}
+
+func TestLoadReferenceSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reference.json")
+	os.WriteFile(path, []byte(`{"resume_file": "resume.txt", "jobs": [
+		{"job": {"job_id": "1", "job_position": "Go Intern"}, "target": 85},
+		{"job": {"job_id": "2", "job_position": "Sales Lead"}, "target": 10}
+	]}`), 0644)
+	set, err := loadReferenceSet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.ResumeFile != filepath.Join(dir, "resume.txt") || set.Jobs[0].Target != 85 {
+		t.Errorf("unexpected set %+v", set)
+	}
+
+	os.WriteFile(path, []byte(`{"jobs": [{"target": 50}, {"target": 150}]}`), 0644)
+	if _, err := loadReferenceSet(path); err == nil {
+		t.Error("expected error for a target outside 0-100")
+	}
+}
+
+func TestShippedReferenceSet(t *testing.T) {
+	set, err := loadReferenceSet(defaultCalibrationSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resume, err := os.ReadFile(set.ResumeFile)
+	if err != nil {
+		t.Fatalf("reference set resume: %v", err)
+	}
+	if len(findSkills(string(resume))) == 0 {
+		t.Error("reference resume lists no recognizable skills")
+	}
+	ids := map[string]bool{}
+	for _, ref := range set.Jobs {
+		if ref.Job.JobID == "" || ids[ref.Job.JobID] || ref.Job.JobDescription == "" {
+			t.Errorf("reference job %+v needs a unique ID and a description", ref.Job)
+		}
+		ids[ref.Job.JobID] = true
+	}
+}
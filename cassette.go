@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	cassetteRecord = "record"
+	cassetteReplay = "replay"
+)
+
+// secretParams are stripped from recorded URLs and ignored when matching,
+// so cassettes can be committed and replayed with any key.
+var secretParams = []string{"api_key"}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"` // empty matches any body
+}
+
+type cassetteResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassette struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// cassetteTransport records responses from next into a cassette file, or
+// replays them from it without any network access.
+//
+// Replay hands out matching interactions in recorded order, so a 429
+// followed by a 200 for the same URL plays back as a retry. Once every
+// match has been used, the last one repeats.
+type cassetteTransport struct {
+	path string
+	mode string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+}
+
+func newCassetteTransport(path, mode string, next http.RoundTripper) (*cassetteTransport, error) {
+	t := &cassetteTransport{path: path, mode: mode, next: next}
+	switch mode {
+	case cassetteRecord:
+		// Start fresh: a recording replaces whatever was there.
+	case cassetteReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("http.cassette_mode must be %q or %q, got %q", cassetteRecord, cassetteReplay, mode)
+	}
+	return t, nil
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	key := cassetteRequest{Method: req.Method, URL: redactURL(req.URL), Body: string(body)}
+
+	if t.mode == cassetteReplay {
+		return t.replay(req, key)
+	}
+	return t.record(req, key)
+}
+
+func (t *cassetteTransport) replay(req *http.Request, key cassetteRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last := -1
+	for i, in := range t.cassette.Interactions {
+		if !in.Request.matches(key) {
+			continue
+		}
+		if !t.used[i] {
+			t.used[i] = true
+			return in.Response.toHTTP(req), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return t.cassette.Interactions[last].Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("cassette %s has no interaction for %s %s", t.path, key.Method, key.URL)
+}
+
+func (t *cassetteTransport) record(req *http.Request, key cassetteRequest) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := cassetteResponse{Status: res.StatusCode, Body: redactSecrets(string(body))}
+	if ct := res.Header.Get("Content-Type"); ct != "" {
+		recorded.Headers = map[string]string{"Content-Type": ct}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, cassetteInteraction{Request: key, Response: recorded})
+	// Saving after every call keeps what was recorded if the run dies.
+	if err := t.save(); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return res, nil
+}
+
+func (t *cassetteTransport) save() error {
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(t.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+func (r cassetteRequest) matches(key cassetteRequest) bool {
+	return r.Method == key.Method && r.URL == key.URL && (r.Body == "" || r.Body == key.Body)
+}
+
+func (r cassetteResponse) toHTTP(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// redactURL drops secret query parameters and sorts the rest, so matching
+// doesn't depend on parameter order.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for _, p := range secretParams {
+		q.Del(p)
+	}
+	clean := *u
+	clean.RawQuery = q.Encode()
+	return clean.String()
+}
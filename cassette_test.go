@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useCassette replays path for the rest of the test, with retry and rate
+// limit waits skipped.
+func useCassette(t *testing.T, path string) {
+	t.Helper()
+	transport, err := newCassetteTransport(path, cassetteReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldClient, oldSleep := httpClient, sleep
+	httpClient = &http.Client{Transport: transport}
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() { httpClient, sleep = oldClient, oldSleep })
+}
+
+// closedAddr returns a local address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// offlineRedis points at a closed port, so every cache lookup misses and
+// every store fails quietly, as with Redis down.
+func offlineRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: closedAddr(t), MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// startSMTPServer accepts mail without authentication and sends each
+// message's DATA on the returned channel.
+func startSMTPServer(t *testing.T) (host string, port int, messages <-chan string) {
+	t.Helper()
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received := make(chan string, 1)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+
+	h, p, _ := net.SplitHostPort(ln.Addr().String())
+	port, _ = strconv.Atoi(p)
+	return h, port, received
+}
+
+func serveSMTP(conn net.Conn, received chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if strings.TrimRight(l, "\r\n") == "." {
+					break
+				}
+				msg.WriteString(l)
+			}
+			received <- msg.String()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// TestPipelineFromCassette runs the whole pipeline against a recorded
+// ScrapingDog and Ollama session, with Redis down.
+func TestPipelineFromCassette(t *testing.T) {
+	useCassette(t, "testdata/cassettes/pipeline.json")
+	dir := t.TempDir()
+	resume := filepath.Join(dir, "resume.txt")
+	os.WriteFile(resume, []byte("Go and SQL developer"), 0644)
+	smtpHost, smtpPort, mail := startSMTPServer(t)
+	withConfig(t, func(c *Config) {
+		c.ScrapingDog.APIKey = "test-key"
+		c.Redis.Addr = closedAddr(t)
+		c.Resume.File = resume
+		c.Storage.IndexFile = filepath.Join(dir, "index.json")
+		c.Storage.ReportFile = filepath.Join(dir, "report.html")
+		c.Storage.DatabaseURL = filepath.Join(dir, "scout.db")
+		c.Email = EmailConfig{From: "scout@example.com", To: "me@example.com", SMTPHost: smtpHost, SMTPPort: smtpPort}
+	})
+
+	if err := runPipeline(pipelineOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(config.Storage.ReportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+
+	// Page 1 is full and page 2 is rate limited once and then empty. Job 1 is
+	// rate limited once, job 2 returns malformed JSON and job 3 an empty
+	// array; the last two are dropped (checked against the index below).
+	if n := strings.Count(report, "<h2>Job Evaluation #"); n != 8 {
+		t.Errorf("report has %d evaluations, want 8", n)
+	}
+	// 80×.4 + 60×.2 + 70×.15 + 70×.15 + 60×.1, with 900+120 tokens each.
+	if n := strings.Count(report, "data-score='71'"); n != 8 {
+		t.Errorf("%d evaluations scored 71, want 8", n)
+	}
+	if n := strings.Count(report, "1020 tokens"); n != 8 {
+		t.Errorf("%d evaluations show 1020 tokens, want 8", n)
+	}
+
+	select {
+	case msg := <-mail:
+		if !strings.Contains(msg, "To: me@example.com") || !strings.Contains(msg, `filename="report.html"`) {
+			t.Errorf("unexpected email:\n%s", msg)
+		}
+	default:
+		t.Error("no email was sent")
+	}
+
+	ctx := context.Background()
+	err = withRepository(ctx, func(repo repository) error {
+		jobs, err := repo.jobHistory(ctx)
+		if err != nil {
+			return err
+		}
+		if len(jobs) != 8 || jobs["2"] != nil || jobs["3"] != nil || jobs["1"] == nil {
+			t.Errorf("database has jobs %v", slices.Collect(maps.Keys(jobs)))
+		}
+		for id, job := range jobs {
+			if job.Description.Profile != "Software Engineer Intern" || len(job.Evaluations) != 1 {
+				t.Errorf("job %s: profile %q, %d evaluations", id, job.Description.Profile, len(job.Evaluations))
+			}
+		}
+		runs, err := repo.runs(ctx, 0)
+		if len(runs) != 1 || runs[0].Evaluations != 8 {
+			t.Errorf("runs = %+v", runs)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"job_id": "42"}]`)
+	}))
+	path := filepath.Join(t.TempDir(), "cassettes", "recorded.json")
+
+	recorder, err := newCassetteTransport(path, cassetteRecord, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+	for range 2 {
+		res, err := client.Get(srv.URL + "/linkedinjobs?job_id=42&api_key=secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("cassette contains the API key")
+	}
+
+	player, err := newCassetteTransport(path, cassetteReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: player}
+	var statuses []int
+	for range 3 {
+		// A different key and parameter order still match.
+		res, err := client.Get(srv.URL + "/linkedinjobs?api_key=other&job_id=42")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		statuses = append(statuses, res.StatusCode)
+		if res.StatusCode == http.StatusOK && string(body) != `[{"job_id": "42"}]` {
+			t.Errorf("replayed body %q", body)
+		}
+	}
+	if statuses[0] != 429 || statuses[1] != 200 || statuses[2] != 200 {
+		t.Errorf("replayed statuses %v, want 429, 200, then the last one repeated", statuses)
+	}
+
+	if _, err := client.Get(srv.URL + "/linkedinjobs?job_id=7"); err == nil {
+		t.Error("expected an error for a request missing from the cassette")
+	}
+}
+
+func TestTalkToOllamaMalformedResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ollama.json")
+	os.WriteFile(path, []byte(`{"interactions": [
+		{"request": {"method": "POST", "url": "http://ollama.test/api/chat"}, "response": {"status": 200, "body": "{\"message\": "}},
+		{"request": {"method": "POST", "url": "http://ollama.test/api/chat"}, "response": {"status": 200, "body": "{\"message\": {\"role\": \"assistant\", \"content\": \"I can't rate this.\"}, \"done\": true}"}}
+	]}`), 0644)
+	useCassette(t, path)
+	withConfig(t, func(c *Config) { c.Ollama.URL = "http://ollama.test/api/chat" })
+
+	if _, err := talkToOllama(context.Background(), ollamaChatURL(), Request{Model: "m"}); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+
+	// Well-formed JSON without a score counts as a format error.
+	rubric := defaultRubric
+	eval, err := evaluateSample(context.Background(), evalSettings{rubric: &rubric}, "m", "system", "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eval.FormatErrors != 1 || eval.ModelScore != 0 {
+		t.Errorf("format errors %d, model score %d", eval.FormatErrors, eval.ModelScore)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runCommand dispatches the subcommands available alongside the default
+// fetch-evaluate-email pipeline.
+func runCommand(name string, args []string) error {
+	switch name {
+	case "run":
+		return runRunCommand(args)
+	case "prompts":
+		return runPromptsCommand(args)
+	case "rank":
+		return runRankCommand(args)
+	case "similar":
+		return runSimilarCommand(args)
+	case "search":
+		return runSearchCommand(args)
+	case "serve":
+		return runServeCommand(args)
+	case "skills":
+		return runSkillsCommand(args)
+	case "trends":
+		return runTrendsCommand(args)
+	case "track":
+		return runTrackCommand(args)
+	case "feedback":
+		return runFeedbackCommand(args)
+	case "calibrate":
+		return runCalibrateCommand(args)
+	case "bench":
+		return runBenchCommand(args)
+	case "devserver":
+		return runDevServerCommand(args)
+	case "config":
+		return runConfigCommand(args)
+	case "db":
+		return runDBCommand(args)
+	default:
+		return fmt.Errorf("unknown command %q (available: run, prompts, rank, similar, search, serve, skills, trends, track, feedback, calibrate, bench, devserver, config, db)", name)
+	}
+}
+
+// runRunCommand runs the pipeline with its flags.
+func runRunCommand(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	var opts pipelineOptions
+	fs.BoolVar(&opts.Offline, "offline", config.Run.Offline, "work from cached and stored jobs only: no ScrapingDog calls and no email")
+	fs.DurationVar(&opts.Since, "since", 0, "offline: only jobs seen within this long, e.g. 72h (default all)")
+	every := fs.Duration("every", 0, "keep running: start a new run this long after the previous one started")
+	metricsAddr := fs.String("metrics-addr", config.Metrics.Addr, "serve Prometheus /metrics on this address while running")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if *metricsAddr != "" {
+		serveMetrics(*metricsAddr)
+	}
+	if *every <= 0 {
+		return runPipeline(opts)
+	}
+
+	// Daemon mode: a failed run is logged and the schedule carries on.
+	for {
+		start := time.Now()
+		if err := runPipeline(opts); err != nil {
+			slog.Error("Run failed", "err", err)
+		}
+		wait := time.Until(start.Add(*every))
+		slog.Info("Waiting for the next run", "wait", wait.Round(time.Second))
+		sleep(wait)
+	}
+}
+
+func runPromptsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: prompts <list|validate> [files...]")
+	}
+
+	switch args[0] {
+	case "list":
+		templates, err := listPromptTemplates()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tSOURCE")
+		for _, t := range templates {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Version, t.Source)
+		}
+		return w.Flush()
+
+	case "validate":
+		fs := flag.NewFlagSet("prompts validate", flag.ContinueOnError)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return validatePrompts(fs.Args())
+
+	default:
+		return fmt.Errorf("unknown prompts subcommand %q", args[0])
+	}
+}
+
+// validatePrompts checks the given template files, or every known template
+// when none are given, and reports all failures rather than stopping at one.
+func validatePrompts(files []string) error {
+	var templates []*PromptTemplate
+	failed := 0
+
+	if len(files) == 0 {
+		all, err := listPromptTemplates()
+		if err != nil {
+			return err
+		}
+		templates = all
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", f, err)
+			failed++
+			continue
+		}
+		t, err := parsePromptTemplate(f, f, string(data))
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", f, err)
+			failed++
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	for _, t := range templates {
+		if err := validatePromptTemplate(t); err != nil {
+			fmt.Printf("FAIL %s (%s): %v\n", t.Name, t.Source, err)
+			failed++
+			continue
+		}
+		fmt.Printf("ok   %s@%s (%s)\n", t.Name, t.Version, t.Source)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d prompt template(s) failed validation", failed)
+	}
+	return nil
+}
+
+// runRankCommand ranks every stored job by embedding similarity to
+// the resume (resume.file), without any chat evaluation.
+func runRankCommand(args []string) error {
+	fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+	limit := fs.Int("n", 20, "number of jobs to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := requireEmbedConfig()
+	if err != nil {
+		return err
+	}
+	resume, err := os.ReadFile(config.Resume.File)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ix, err := loadStoredJobIndex(ctx)
+	if err != nil {
+		return err
+	}
+	redisDB := newRedisClient()
+	defer redisDB.Close()
+	resumeVec, err := getCachedEmbedding(ctx, redisDB, cfg, resumeEmbeddingID(string(resume)), string(resume))
+	if err != nil {
+		return fmt.Errorf("failed to embed resume: %w", err)
+	}
+
+	return printRankedJobs(rankBySimilarity(ctx, redisDB, cfg, [][]float64{resumeVec}, slices.Collect(maps.Values(ix.Jobs))), *limit)
+}
+
+// runSimilarCommand ranks stored jobs by similarity to the given liked jobs.
+func runSimilarCommand(args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ContinueOnError)
+	limit := fs.Int("n", 20, "number of jobs to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	liked := fs.Args()
+	if len(liked) == 0 {
+		return errors.New("usage: similar [-n 20] <jobID>...")
+	}
+
+	cfg, err := requireEmbedConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ix, err := loadStoredJobIndex(ctx)
+	if err != nil {
+		return err
+	}
+	redisDB := newRedisClient()
+	defer redisDB.Close()
+
+	var queries [][]float64
+	var candidates []*indexedJob
+	for id, job := range ix.Jobs {
+		if !slices.Contains(liked, id) {
+			candidates = append(candidates, job)
+			continue
+		}
+		vec, err := storedEmbedding(ctx, redisDB, cfg, job)
+		if err != nil {
+			return fmt.Errorf("failed to embed liked JobID %s: %w", id, err)
+		}
+		queries = append(queries, vec)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("none of the liked jobs %v are in the job history", liked)
+	}
+
+	return printRankedJobs(rankBySimilarity(ctx, redisDB, cfg, queries, candidates), *limit)
+}
+
+func requireEmbedConfig() (embedSettings, error) {
+	cfg, err := loadEmbedConfig(config)
+	if err != nil {
+		return cfg, err
+	}
+	if !cfg.enabled() {
+		return cfg, errors.New("set embed.model (EMBED_MODEL) to use embeddings")
+	}
+	return cfg, nil
+}
+
+func printRankedJobs(ranked []rankedJob, limit int) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SIMILARITY\tJOB ID\tTITLE\tCOMPANY")
+	for i, r := range ranked {
+		if i >= limit {
+			break
+		}
+		fmt.Fprintf(w, "%.3f\t%s\t%s\t%s\n", r.Similarity, r.Desc.JobID, r.Desc.JobPosition, r.Desc.CompanyName)
+	}
+	return w.Flush()
+}
+
+// runSearchCommand finds the historical jobs most relevant to a free-text query.
+func runSearchCommand(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("n", 10, "number of jobs to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New(`usage: search [-n 10] "distributed systems rust remote"`)
+	}
+
+	var results []searchResult
+	ctx := context.Background()
+	err := withRepository(ctx, func(repo repository) error {
+		var err error
+		results, err = searchJobIndex(ctx, repo, strings.Join(fs.Args(), " "), *limit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SIMILARITY\tSCORE\tJOB ID\tTITLE\tCOMPANY\tFIRST SEEN")
+	for _, r := range results {
+		score := "-"
+		if r.Score != nil {
+			score = strconv.Itoa(*r.Score)
+		}
+		fmt.Fprintf(w, "%.3f\t%s\t%s\t%s\t%s\t%s\n", r.Similarity, score, r.JobID, r.Title, r.Company, r.FirstSeen.Format("2006-01-02"))
+	}
+	return w.Flush()
+}
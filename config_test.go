@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withConfig runs the rest of the test with a modified copy of the config.
+func withConfig(t *testing.T, mutate func(c *Config)) {
+	t.Helper()
+	old := config
+	c := *old
+	mutate(&c)
+	config = &c
+	t.Cleanup(func() { config = old })
+}
+
+func TestConfigDefaults(t *testing.T) {
+	c := defaultConfig()
+	if c.ScrapingDog.MaxRetries != 5 || c.ScrapingDog.RateLimitDelay != 2*time.Second || c.Ollama.Temperature != 0.3 {
+		t.Errorf("defaults = %+v", c.ScrapingDog)
+	}
+	if c.Email.SMTPPort != 587 || c.Storage.ReportFile != "LinkedinEvaluations.html" {
+		t.Errorf("defaults = %+v, %+v", c.Email, c.Storage)
+	}
+	for _, f := range c.fields() {
+		if f.Env == "" {
+			t.Errorf("%s has no env tag", f.Key)
+		}
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.json")
+	os.WriteFile(file, []byte(`{
+		"ollama": {"model": "from-file", "temperature": 0.7},
+		"redis": {"addr": "file:6379", "db": 2},
+		"scrapingdog": {"rate_limit_delay": "500ms"}
+	}`), 0644)
+	envFile := filepath.Join(dir, "test.env")
+	os.WriteFile(envFile, []byte("REDIS_ADDR=dotenv:6379\n"), 0644)
+	t.Setenv("OLLAMA_MODEL", "from-env")
+	t.Setenv("REDIS_ADDR", "")
+	os.Unsetenv("REDIS_ADDR") // so the .env file can set it
+
+	c, err := loadConfig(configSources{File: file, EnvFile: envFile, Sets: []string{"ollama.temperature=0.1"}, explicitFile: true, explicitEnv: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Ollama.Model != "from-env" || c.Ollama.Temperature != 0.1 {
+		t.Errorf("ollama = %+v, want env model and flag temperature", c.Ollama)
+	}
+	if c.Redis.Addr != "dotenv:6379" || c.Redis.DB != 2 {
+		t.Errorf("redis = %+v, want .env addr and file db", c.Redis)
+	}
+	if c.ScrapingDog.RateLimitDelay != 500*time.Millisecond {
+		t.Errorf("rate limit delay = %s", c.ScrapingDog.RateLimitDelay)
+	}
+	if c.sources["ollama.model"] != "env OLLAMA_MODEL" || c.sources["ollama.temperature"] != "flag" || c.sources["redis.db"] != "file "+file {
+		t.Errorf("sources = %v", c.sources)
+	}
+}
+
+func TestLoadConfigListsEveryProblem(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(file, []byte(`{"ollama": {"temperature": 5, "modle": "x"}, "scrapingdog": {"max_retries": "lots"}}`), 0644)
+	t.Setenv("SMTP_PORT", "0")
+	t.Setenv("EVAL_SAMPLES", "-1")
+
+	_, err := loadConfig(configSources{File: file, Sets: []string{"nope=1", "redis.db"}, explicitFile: true})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{`unknown key "ollama.modle"`, "scrapingdog.max_retries must be an integer", "ollama.temperature must be between 0 and 2",
+		"email.smtp_port", `unknown key "nope"`, `"redis.db": want key=value`, "eval.samples must be a positive integer"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error is missing %q:\n%v", want, err)
+		}
+	}
+
+	// A missing default config file is fine; a missing named one isn't.
+	if _, err := loadConfig(configSources{File: filepath.Join(t.TempDir(), "missing.json"), explicitFile: true}); err == nil {
+		t.Error("expected an error for a missing -config file")
+	}
+}
+
+func TestLoadConfigFeatureSections(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(file, []byte(`{"eval": {"samples": 3, "models": "a, b"}, "filter": {"max_age": "72h", "workplace": "remote"}, "feedback": {"min_samples": 1000000}}`), 0644)
+	t.Setenv("TRIAGE_MODEL", "gemma3:1b")
+
+	c, err := loadConfig(configSources{File: file, Sets: []string{"feedback.examples=0"}, explicitFile: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampling, err := loadSamplingConfig(c)
+	if err != nil || sampling.Samples != 3 || strings.Join(sampling.Models, ",") != "a,b" {
+		t.Errorf("sampling = %+v, %v", sampling, err)
+	}
+	filters, err := loadJobFilters(c)
+	if err != nil || filters.MaxAge != 72*time.Hour || len(filters.Workplaces) != 1 {
+		t.Errorf("filters = %+v, %v", filters, err)
+	}
+	if c.Triage.Model != "gemma3:1b" || c.Triage.Threshold != 50 || c.Feedback.Examples != 0 || c.Feedback.MinSamples != 1000000 {
+		t.Errorf("triage = %+v, feedback = %+v", c.Triage, c.Feedback)
+	}
+	if c.sources["eval.samples"] != "file "+file || c.sources["triage.model"] != "env TRIAGE_MODEL" || c.sources["feedback.examples"] != "flag" {
+		t.Errorf("sources = %v", c.sources)
+	}
+}
+
+func TestSplitGlobalFlags(t *testing.T) {
+	global, rest := splitGlobalFlags([]string{"-config", "c.json", "--set=ollama.model=x", "-set", "redis.db=1", "run", "-offline"})
+	if strings.Join(global, " ") != "-config c.json --set=ollama.model=x -set redis.db=1" || strings.Join(rest, " ") != "run -offline" {
+		t.Errorf("global = %q, rest = %q", global, rest)
+	}
+	global, rest = splitGlobalFlags([]string{"--offline"})
+	if len(global) != 0 || len(rest) != 1 {
+		t.Errorf("global = %q, rest = %q", global, rest)
+	}
+}
+
+func TestPrintConfigRedactsSecrets(t *testing.T) {
+	c := defaultConfig()
+	c.ScrapingDog.APIKey = "sk-live-123"
+	c.Email.Password = "hunter2"
+	out := filepath.Join(t.TempDir(), "show.txt")
+	f, _ := os.Create(out)
+	if err := printConfig(f, c, false); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	data, _ := os.ReadFile(out)
+	if strings.Contains(string(data), "sk-live-123") || strings.Contains(string(data), "hunter2") {
+		t.Errorf("secrets leaked:\n%s", data)
+	}
+	if !strings.Contains(string(data), "scrapingdog.api_key") || !strings.Contains(string(data), redactedValue) {
+		t.Errorf("output:\n%s", data)
+	}
+}
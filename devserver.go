@@ -0,0 +1,344 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultDevFixtures = "devdata"
+
+// devServerConfig controls the fake ScrapingDog and Ollama endpoints.
+type devServerConfig struct {
+	FixturesDir        string
+	ScrapingDogLatency time.Duration
+	OllamaLatency      time.Duration
+	RateLimitEvery     int // every Nth ScrapingDog request gets a 429
+	BadJSONEvery       int // every Nth chat response is truncated JSON
+	GarbledEvery       int // every Nth chat response is valid JSON without a score
+}
+
+// chatScriptEntry is a canned chat reply. Entries are tried in order and
+// the first whose Match appears in the prompt (and Model, if set) wins.
+type chatScriptEntry struct {
+	Match   string `json:"match"`
+	Model   string `json:"model,omitempty"`
+	Status  int    `json:"status,omitempty"`  // default 200
+	Content string `json:"content,omitempty"` // the assistant message
+	Raw     string `json:"raw,omitempty"`     // sent verbatim instead of a JSON response
+	Latency string `json:"latency,omitempty"` // overrides -ollama-latency, e.g. "3s"
+}
+
+// devFixtures is what the fake ScrapingDog serves: listings from
+// listings.json, paged like the real API, and details from jobs/<id>.json.
+// Listings without a detail file get one made up from the listing.
+type devFixtures struct {
+	Listings []JobListing
+	Details  map[string][]JobDescription
+	Script   []chatScriptEntry
+}
+
+func loadDevFixtures(dir string) (devFixtures, error) {
+	fx := devFixtures{Details: map[string][]JobDescription{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, "listings.json"))
+	if err != nil {
+		return fx, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	if err := json.Unmarshal(data, &fx.Listings); err != nil {
+		return fx, fmt.Errorf("failed to decode %s/listings.json: %w", dir, err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "jobs", "*.json"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fx, err
+		}
+		// Accept the API's one-element array or a bare object.
+		var descs []JobDescription
+		if err := json.Unmarshal(data, &descs); err != nil {
+			var desc JobDescription
+			if err2 := json.Unmarshal(data, &desc); err2 != nil {
+				return fx, fmt.Errorf("failed to decode %s: %w", f, err)
+			}
+			descs = []JobDescription{desc}
+		}
+		fx.Details[strings.TrimSuffix(filepath.Base(f), ".json")] = descs
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "chat_script.json"))
+	if err == nil {
+		if err := json.Unmarshal(data, &fx.Script); err != nil {
+			return fx, fmt.Errorf("failed to decode %s/chat_script.json: %w", dir, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fx, err
+	}
+	return fx, nil
+}
+
+type devServer struct {
+	cfg         devServerConfig
+	fixtures    devFixtures
+	rubric      *Rubric
+	scrapeCount atomic.Int64
+	chatCount   atomic.Int64
+}
+
+func newDevServerMux(cfg devServerConfig) (*http.ServeMux, error) {
+	fx, err := loadDevFixtures(cfg.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+	rubric, err := loadRubric(config)
+	if err != nil {
+		return nil, err
+	}
+	s := &devServer{cfg: cfg, fixtures: fx, rubric: rubric}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /linkedinjobs", s.handleLinkedInJobs)
+	mux.HandleFunc("POST /api/chat", s.handleChat)
+	mux.HandleFunc("POST /api/embeddings", s.handleEmbeddings)
+	return mux, nil
+}
+
+// nth reports whether the count-th call falls on an every-N boundary.
+func nth(count int64, every int) bool {
+	return every > 0 && count%int64(every) == 0
+}
+
+// handleLinkedInJobs serves ScrapingDog's /linkedinjobs: a job_id returns
+// the detail array, otherwise a page of listings.
+func (s *devServer) handleLinkedInJobs(w http.ResponseWriter, r *http.Request) {
+	n := s.scrapeCount.Add(1)
+	sleep(s.cfg.ScrapingDogLatency)
+	q := r.URL.Query()
+	if q.Get("api_key") == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"success": false, "message": "api_key is required"})
+		return
+	}
+	if nth(n, s.cfg.RateLimitEvery) {
+		slog.Info("devserver: rate limiting", "query", r.URL.RawQuery)
+		writeJSON(w, http.StatusTooManyRequests, map[string]any{"success": false, "message": "rate limit exceeded"})
+		return
+	}
+
+	if id := q.Get("job_id"); id != "" {
+		writeJSON(w, http.StatusOK, s.jobDetail(id))
+		return
+	}
+
+	page := 1
+	if p := q.Get("page"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"success": false, "message": "invalid page"})
+			return
+		}
+		page = v
+	}
+	const pageSize = 10
+	start := min((page-1)*pageSize, len(s.fixtures.Listings))
+	end := min(start+pageSize, len(s.fixtures.Listings))
+	writeJSON(w, http.StatusOK, s.fixtures.Listings[start:end])
+}
+
+// jobDetail returns the fixture for id, one made up from its listing, or an
+// empty array like the real API gives for unknown jobs.
+func (s *devServer) jobDetail(id string) []JobDescription {
+	if descs, ok := s.fixtures.Details[id]; ok {
+		return descs
+	}
+	for _, l := range s.fixtures.Listings {
+		if l.JobID == id {
+			return []JobDescription{{
+				JobPosition:    l.JobPosition,
+				JobLocation:    l.JobLocation,
+				CompanyName:    l.CompanyName,
+				JobPostingTime: "1 day ago",
+				JobDescription: fmt.Sprintf("%s at %s. Placeholder description served by devserver.", l.JobPosition, l.CompanyName),
+				SeniorityLevel: "Internship",
+				EmploymentType: "Internship",
+				JobApplyLink:   l.JobLink,
+			}}
+		}
+	}
+	return []JobDescription{}
+}
+
+func (s *devServer) handleChat(w http.ResponseWriter, r *http.Request) {
+	n := s.chatCount.Add(1)
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid request JSON"})
+		return
+	}
+	prompt := ""
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			prompt = m.Content
+		}
+	}
+
+	latency := s.cfg.OllamaLatency
+	if entry := s.scripted(req.Model, prompt); entry != nil {
+		if entry.Latency != "" {
+			if d, err := time.ParseDuration(entry.Latency); err == nil {
+				latency = d
+			}
+		}
+		sleep(latency)
+		if entry.Raw != "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(cmp.Or(entry.Status, http.StatusOK))
+			w.Write([]byte(entry.Raw))
+			return
+		}
+		if status := cmp.Or(entry.Status, http.StatusOK); status != http.StatusOK {
+			writeJSON(w, status, map[string]any{"error": entry.Content})
+			return
+		}
+		writeJSON(w, http.StatusOK, chatResponse(req.Model, prompt, entry.Content))
+		return
+	}
+
+	sleep(latency)
+	switch {
+	case nth(n, s.cfg.BadJSONEvery):
+		slog.Info("devserver: truncated JSON", "chat", n)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "` + req.Model + `", "message": {"role": "assistant", "content": "Fit Sc`))
+	case nth(n, s.cfg.GarbledEvery):
+		slog.Info("devserver: garbled output", "chat", n)
+		writeJSON(w, http.StatusOK, chatResponse(req.Model, prompt, "I'm sorry, I can't evaluate this job."))
+	default:
+		writeJSON(w, http.StatusOK, chatResponse(req.Model, prompt, s.defaultReply(prompt)))
+	}
+}
+
+func (s *devServer) scripted(model, prompt string) *chatScriptEntry {
+	for i, e := range s.fixtures.Script {
+		if (e.Model == "" || e.Model == model) && strings.Contains(prompt, e.Match) {
+			return &s.fixtures.Script[i]
+		}
+	}
+	return nil
+}
+
+// promptScore derives a stable 30-90 score from the prompt, so the same job
+// always gets the same score and different jobs spread out.
+func promptScore(prompt string) int {
+	h := fnv.New32a()
+	h.Write([]byte(prompt))
+	return 30 + int(h.Sum32()%61)
+}
+
+// defaultReply answers in whichever format the prompt asks for: triage,
+// salary or a full evaluation with every rubric criterion.
+func (s *devServer) defaultReply(prompt string) string {
+	score := promptScore(prompt)
+	switch {
+	case strings.Contains(prompt, "Triage Score:"):
+		return fmt.Sprintf("Triage Score: %d/100", score)
+	case strings.Contains(prompt, "Salary: none"):
+		return "Salary: none"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Fit Score: %d/100\n\n", score)
+	for i, c := range s.rubric.Criteria {
+		sub := clampScore(score + (i%3-1)*10)
+		fmt.Fprintf(&sb, "%s: %d/100 - Deterministic devserver score\n", c.Name, sub)
+	}
+	sb.WriteString("\nMatching Qualifications:\n- Go\n- SQL\n\nMissing Qualifications:\n- Kubernetes\n\nResume Suggestions:\n- Mention any container work.\n")
+	return sb.String()
+}
+
+// devServerTokenTime is the generation time reported per completion token,
+// a steady 50 tokens/s.
+const devServerTokenTime = 20 * time.Millisecond
+
+func chatResponse(model, prompt, content string) Response {
+	completion := len(strings.Fields(content))
+	return Response{
+		Model:           model,
+		CreatedAt:       time.Now(),
+		Message:         Message{Role: "assistant", Content: content},
+		Done:            true,
+		PromptEvalCount: len(strings.Fields(prompt)),
+		EvalCount:       completion,
+		EvalDuration:    int64(completion) * int64(devServerTokenTime),
+	}
+}
+
+// handleEmbeddings returns a bag-of-words vector, hashed into a fixed number
+// of buckets, so texts sharing words come out similar.
+func (s *devServer) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req ollamaEmbedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid request JSON"})
+		return
+	}
+	sleep(s.cfg.OllamaLatency)
+
+	vec := make([]float64, 64)
+	for _, word := range strings.Fields(strings.ToLower(req.Prompt)) {
+		h := fnv.New32a()
+		h.Write([]byte(strings.Trim(word, ".,;:()")))
+		vec[h.Sum32()%uint32(len(vec))]++
+	}
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+	writeJSON(w, http.StatusOK, ollamaEmbedResponse{Embedding: vec})
+}
+
+// runDevServerCommand serves fake ScrapingDog and Ollama APIs for local
+// development. Point SCRAPINGDOG_BASE_URL, OLLAMA_URL and EMBED_URL at it.
+func runDevServerCommand(args []string) error {
+	fs := flag.NewFlagSet("devserver", flag.ContinueOnError)
+	addr := fs.String("addr", ":8090", "listen address")
+	cfg := devServerConfig{}
+	fs.StringVar(&cfg.FixturesDir, "fixtures", defaultDevFixtures, "directory with listings.json, jobs/<id>.json and an optional chat_script.json")
+	fs.DurationVar(&cfg.ScrapingDogLatency, "scrapingdog-latency", 0, "delay before each ScrapingDog response")
+	fs.DurationVar(&cfg.OllamaLatency, "ollama-latency", 0, "delay before each Ollama response")
+	fs.IntVar(&cfg.RateLimitEvery, "rate-limit-every", 0, "answer every Nth ScrapingDog request with 429 (0 = never)")
+	fs.IntVar(&cfg.BadJSONEvery, "bad-json-every", 0, "truncate every Nth chat response (0 = never)")
+	fs.IntVar(&cfg.GarbledEvery, "garbled-every", 0, "make every Nth chat reply ignore the format (0 = never)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mux, err := newDevServerMux(cfg)
+	if err != nil {
+		return err
+	}
+	base := "http://localhost" + *addr
+	if !strings.HasPrefix(*addr, ":") {
+		base = "http://" + *addr
+	}
+	slog.Info("Serving fake ScrapingDog and Ollama", "addr", *addr, "fixtures", cfg.FixturesDir)
+	slog.Info("Point the scout at the dev server", "SCRAPINGDOG_BASE_URL", base, "OLLAMA_URL", base+"/api/chat", "EMBED_URL", base+"/api/embeddings")
+	return http.ListenAndServe(*addr, mux)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// startDevServer runs the fake APIs on the shipped fixtures and points the
+// pipeline at them.
+func startDevServer(t *testing.T, cfg devServerConfig) {
+	t.Helper()
+	if cfg.FixturesDir == "" {
+		cfg.FixturesDir = defaultDevFixtures
+	}
+	mux, err := newDevServerMux(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	oldClient, oldSleep := httpClient, sleep
+	httpClient = &http.Client{}
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() { httpClient, sleep = oldClient, oldSleep })
+
+	withConfig(t, func(c *Config) {
+		c.ScrapingDog.APIKey = "dev"
+		c.ScrapingDog.BaseURL = srv.URL
+		c.Ollama.URL = srv.URL + "/api/chat"
+	})
+}
+
+func TestDevServerScrapingDog(t *testing.T) {
+	startDevServer(t, devServerConfig{RateLimitEvery: 4})
+
+	// 12 listings come back as a full page and a short one, with every
+	// fourth request rate limited and retried.
+	listings, err := getJobListings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 12 {
+		t.Fatalf("got %d listings, want 12", len(listings))
+	}
+
+	descs := processJobListings(context.Background(), offlineRedis(t), listings[9:])
+	if len(descs) != 3 {
+		t.Fatalf("got %d descriptions, want 3", len(descs))
+	}
+	for _, d := range descs {
+		if d.JobDescription == "" {
+			t.Errorf("job %s has no description", d.JobID)
+		}
+	}
+
+	// Unknown jobs come back as an empty array, which is an error.
+	if _, err := getJobDescription(context.Background(), offlineRedis(t), JobListing{JobID: "404"}); err == nil {
+		t.Error("expected an error for an unknown job")
+	}
+}
+
+func TestDevServerChat(t *testing.T) {
+	startDevServer(t, devServerConfig{BadJSONEvery: 3, GarbledEvery: 4})
+	rubric := defaultRubric
+	s := evalSettings{rubric: &rubric}
+
+	first, err := evaluateSample(context.Background(), s, "m", "system", "Software Engineer Intern at Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.FormatErrors != 0 || first.ModelScore < 30 || first.ModelScore > 90 {
+		t.Errorf("default reply: format errors %d, score %d", first.FormatErrors, first.ModelScore)
+	}
+	second, err := evaluateSample(context.Background(), s, "m", "system", "Software Engineer Intern at Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ModelScore != first.ModelScore || second.Score != first.Score {
+		t.Errorf("same prompt scored %d then %d", first.ModelScore, second.ModelScore)
+	}
+
+	if _, err := evaluateSample(context.Background(), s, "m", "system", "third"); err == nil {
+		t.Error("expected the third response to be truncated JSON")
+	}
+	garbled, err := evaluateSample(context.Background(), s, "m", "system", "fourth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if garbled.FormatErrors != 1 {
+		t.Errorf("garbled reply: format errors %d, want 1", garbled.FormatErrors)
+	}
+
+	// The shipped script pins the Staff role low.
+	scripted, err := evaluateSample(context.Background(), s, "m", "system", "Title: Staff Software Engineer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scripted.ModelScore != 8 {
+		t.Errorf("scripted reply scored %d, want 8", scripted.ModelScore)
+	}
+}
@@ -1,21 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/gomail.v2"
-	"log"
-	"os"
 	"time"
 )
 
-func sendEvaluationsEmail() error {
+func sendEvaluationsEmail(ctx context.Context) (err error) {
+	_, span := tracer.Start(ctx, "email", trace.WithAttributes(attribute.String("email.smtp_host", config.Email.SMTPHost)))
+	defer func() { endSpan(span, err) }()
+	start := time.Now()
 	m := gomail.NewMessage()
 
-	from := os.Getenv("EMAIL_FROM")
-	to := os.Getenv("EMAIL_TO")
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := 587 // or parse from env
-	password := os.Getenv("EMAIL_PASSWORD")
+	from := config.Email.From
+	to := config.Email.To
+	smtpHost := config.Email.SMTPHost
+	smtpPort := config.Email.SMTPPort
+	password := config.Email.Password
 
 	// Format current time nicely for the subject header with am/pm in local timezone
 	now := time.Now().Format("Jan 2 3:04 PM MST")
@@ -24,14 +28,16 @@ func sendEvaluationsEmail() error {
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", "LinkedIn Evaluations - "+now)
 	m.SetBody("text/plain", "Hello,\n\nPlease find the LinkedIn Evaluations attached as an HTML file.\n\nThanks,\nLinkedIn Job Scout")
-	m.Attach("LinkedinEvaluations.html")
+	m.Attach(config.Storage.ReportFile)
 
 	d := gomail.NewDialer(smtpHost, smtpPort, from, password)
 
 	if err := d.DialAndSend(m); err != nil {
+		emailsSent.WithLabelValues("failed").Inc()
 		return fmt.Errorf("could not send email: %v", err)
 	}
+	emailsSent.WithLabelValues("sent").Inc()
 
-	log.Println("✅ Email sent successfully!")
+	logStage(stageEmail).Info("Email sent", "to", to, "attachment", config.Storage.ReportFile, durationMS(start))
 	return nil
 }
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	embedProviderOllama = "ollama"
+	embedProviderOpenAI = "openai"
+
+	defaultOllamaEmbedURL = "http://localhost:11434/api/embeddings"
+	defaultOpenAIEmbedURL = "https://api.openai.com/v1/embeddings"
+)
+
+// embedSettings selects the embeddings endpoint. Embeddings are off unless
+// embed.model is set.
+type embedSettings struct {
+	Provider string
+	URL      string
+	Model    string
+	APIKey   string
+}
+
+func loadEmbedConfig(c *Config) (embedSettings, error) {
+	cfg := embedSettings(c.Embed)
+	if cfg.Provider == "" {
+		cfg.Provider = embedProviderOllama
+	}
+
+	switch cfg.Provider {
+	case embedProviderOllama:
+		if cfg.URL == "" {
+			cfg.URL = defaultOllamaEmbedURL
+		}
+	case embedProviderOpenAI:
+		if cfg.URL == "" {
+			cfg.URL = defaultOpenAIEmbedURL
+		}
+	default:
+		return cfg, fmt.Errorf("embed.provider must be %q or %q, got %q", embedProviderOllama, embedProviderOpenAI, cfg.Provider)
+	}
+	return cfg, nil
+}
+
+func (c embedSettings) enabled() bool {
+	return c.Model != ""
+}
+
+type ollamaEmbedRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type ollamaEmbedResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+type openAIEmbedRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type openAIEmbedResponse struct {
+	Data []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// getEmbedding calls Ollama's /api/embeddings or an OpenAI-compatible
+// /v1/embeddings endpoint depending on the configured provider.
+func getEmbedding(ctx context.Context, cfg embedSettings, text string) (vec []float64, err error) {
+	ctx, span := tracer.Start(ctx, "embed", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("model", cfg.Model), attribute.String("provider", cfg.Provider)))
+	defer func() { endSpan(span, err) }()
+
+	var body any = ollamaEmbedRequest{Model: cfg.Model, Prompt: text}
+	if cfg.Provider == embedProviderOpenAI {
+		body = openAIEmbedRequest{Model: cfg.Model, Input: text}
+	}
+	reqJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send embedding request: %w", redactErr(err))
+	}
+	defer res.Body.Close()
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected embedding status: %s, body: %s", res.Status, string(bodyBytes))
+	}
+
+	if cfg.Provider == embedProviderOpenAI {
+		var out openAIEmbedResponse
+		if err := json.Unmarshal(bodyBytes, &out); err != nil {
+			return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+		}
+		if len(out.Data) > 0 {
+			vec = out.Data[0].Embedding
+		}
+	} else {
+		var out ollamaEmbedResponse
+		if err := json.Unmarshal(bodyBytes, &out); err != nil {
+			return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+		}
+		vec = out.Embedding
+	}
+	if len(vec) == 0 {
+		return nil, errors.New("embedding response contained no vector")
+	}
+	return vec, nil
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// embeddingText is what gets embedded for a job: the fields that describe
+// the work, without apply links or recruiter names.
+func embeddingText(desc JobDescription) string {
+	return strings.Join([]string{
+		desc.JobPosition,
+		desc.CompanyName,
+		desc.JobLocation,
+		desc.SeniorityLevel,
+		desc.JobFunction,
+		desc.Industries,
+		desc.JobDescription,
+	}, "\n")
+}
+
+func embeddingCacheKey(model, id string) string {
+	return fmt.Sprintf("embedding:%s:%s", model, id)
+}
+
+// getCachedEmbedding returns the embedding stored under id, computing and
+// caching it next to the job description when it is missing.
+func getCachedEmbedding(ctx context.Context, redisDB *redis.Client, cfg embedSettings, id, text string) ([]float64, error) {
+	key := embeddingCacheKey(cfg.Model, id)
+	if cached, err := redisDB.Get(ctx, key).Result(); err == nil {
+		var vec []float64
+		if err := json.Unmarshal([]byte(cached), &vec); err == nil {
+			return vec, nil
+		}
+	} else if err != redis.Nil {
+		logStage(stageCache).Warn("Embedding cache error", "job_id", id, "model", cfg.Model, "err", err)
+	}
+
+	vec, err := getEmbedding(ctx, cfg, text)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(vec)
+	if err == nil {
+		err = redisDB.Set(ctx, key, data, 24*time.Hour).Err()
+	}
+	if err != nil {
+		logStage(stageCache).Warn("Failed to cache embedding", "job_id", id, "model", cfg.Model, "err", err)
+	}
+	return vec, nil
+}
+
+func resumeEmbeddingID(resume string) string {
+	sum := sha256.Sum256([]byte(resume))
+	return "resume:" + hex.EncodeToString(sum[:8])
+}
+
+// jobSimilarities embeds the resume and every job and returns each job's
+// cosine similarity to the resume, keyed by JobID.
+func jobSimilarities(ctx context.Context, redisDB *redis.Client, cfg embedSettings, resume string, jobs []JobDescription) (map[string]float64, error) {
+	resumeVec, err := getCachedEmbedding(ctx, redisDB, cfg, resumeEmbeddingID(resume), resume)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed resume: %w", err)
+	}
+
+	sims := make(map[string]float64, len(jobs))
+	for _, job := range jobs {
+		vec, err := getCachedEmbedding(ctx, redisDB, cfg, job.JobID, embeddingText(job))
+		if err != nil {
+			logStage(stageEmbed).Warn("Failed to embed job", "job_id", job.JobID, "model", cfg.Model, "err", err)
+			continue
+		}
+		sims[job.JobID] = cosineSimilarity(resumeVec, vec)
+	}
+	return sims, nil
+}
+
+type rankedJob struct {
+	Desc       JobDescription
+	Similarity float64
+}
+
+// rankBySimilarity scores every job against the query vectors, keeping the
+// best match per job, and returns them most similar first.
+func rankBySimilarity(ctx context.Context, redisDB *redis.Client, cfg embedSettings, queries [][]float64, jobs []*indexedJob) []rankedJob {
+	var ranked []rankedJob
+	for _, job := range jobs {
+		vec, err := storedEmbedding(ctx, redisDB, cfg, job)
+		if err != nil {
+			logStage(stageEmbed).Warn("Failed to embed job", "job_id", job.Description.JobID, "model", cfg.Model, "err", err)
+			continue
+		}
+		best := -1.0
+		for _, q := range queries {
+			best = math.Max(best, cosineSimilarity(q, vec))
+		}
+		ranked = append(ranked, rankedJob{Desc: job.Description, Similarity: best})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Similarity > ranked[j].Similarity
+	})
+	return ranked
+}
+
+// storedEmbedding is the job's stored vector when it came from cfg's model,
+// and otherwise a fresh (cached) embedding.
+func storedEmbedding(ctx context.Context, redisDB *redis.Client, cfg embedSettings, job *indexedJob) ([]float64, error) {
+	if job.EmbeddingModel == cfg.Model && len(job.Embedding) > 0 {
+		return job.Embedding, nil
+	}
+	return getCachedEmbedding(ctx, redisDB, cfg, job.Description.JobID, embeddingText(job.Description))
+}
+
+// cachedJobDescriptions loads every job description still in the Redis cache.
+func cachedJobDescriptions(ctx context.Context, redisDB *redis.Client) ([]JobDescription, error) {
+	var descs []JobDescription
+	iter := redisDB.Scan(ctx, 0, "jobID:*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		desc, err := getFromCache(ctx, redisDB, key)
+		if err != nil {
+			logStage(stageCache).Warn("Skipping unreadable cache entry", "key", key, "err", err)
+			continue
+		}
+		if desc.JobID == "" {
+			desc.JobID = strings.TrimPrefix(key, "jobID:")
+		}
+		descs = append(descs, desc)
+	}
+	return descs, iter.Err()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b []float64
+		want float64
+	}{
+		{[]float64{1, 0}, []float64{1, 0}, 1},
+		{[]float64{1, 0}, []float64{0, 1}, 0},
+		{[]float64{1, 2}, []float64{-1, -2}, -1},
+		{[]float64{1, 2}, []float64{1}, 0},
+		{[]float64{0, 0}, []float64{1, 1}, 0},
+	}
+	for _, c := range cases {
+		if got := cosineSimilarity(c.a, c.b); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestGetEmbeddingProviders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/api/embeddings":
+			if body["prompt"] != "hello" {
+				t.Errorf("ollama request missing prompt: %v", body)
+			}
+			w.Write([]byte(`{"embedding":[0.1,0.2]}`))
+		case "/v1/embeddings":
+			if body["input"] != "hello" || r.Header.Get("Authorization") != "Bearer key" {
+				t.Errorf("openai request missing input or auth: %v", body)
+			}
+			w.Write([]byte(`{"data":[{"embedding":[0.3,0.4]}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	vec, err := getEmbedding(context.Background(), embedSettings{Provider: embedProviderOllama, URL: srv.URL + "/api/embeddings", Model: "m"}, "hello")
+	if err != nil || len(vec) != 2 || vec[0] != 0.1 {
+		t.Errorf("ollama embedding = %v, %v", vec, err)
+	}
+
+	vec, err = getEmbedding(context.Background(), embedSettings{Provider: embedProviderOpenAI, URL: srv.URL + "/v1/embeddings", Model: "m", APIKey: "key"}, "hello")
+	if err != nil || len(vec) != 2 || vec[0] != 0.3 {
+		t.Errorf("openai embedding = %v, %v", vec, err)
+	}
+}
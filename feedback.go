@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type FeedbackKind string
+
+const (
+	FeedbackGoodMatch FeedbackKind = "good_match"
+	FeedbackBadMatch  FeedbackKind = "bad_match"
+	FeedbackTooHigh   FeedbackKind = "too_high"
+	FeedbackTooLow    FeedbackKind = "too_low"
+)
+
+var allFeedbackKinds = []FeedbackKind{FeedbackGoodMatch, FeedbackBadMatch, FeedbackTooHigh, FeedbackTooLow}
+
+// feedbackLabels are shown on links and in few-shot examples.
+var feedbackLabels = map[FeedbackKind]string{
+	FeedbackGoodMatch: "good match",
+	FeedbackBadMatch:  "bad match",
+	FeedbackTooHigh:   "score too high",
+	FeedbackTooLow:    "score too low",
+}
+
+func parseFeedbackKind(s string) (FeedbackKind, error) {
+	kind := FeedbackKind(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := feedbackLabels[kind]; ok {
+		return kind, nil
+	}
+	names := make([]string, len(allFeedbackKinds))
+	for i, k := range allFeedbackKinds {
+		names[i] = string(k)
+	}
+	return "", fmt.Errorf("unknown feedback %q (available: %s)", s, strings.Join(names, ", "))
+}
+
+// Feedback is one verdict on a job's evaluation. Score is the evaluation's
+// score before any feedback adjustment, at the time the verdict was given.
+type Feedback struct {
+	Kind  FeedbackKind `json:"kind"`
+	Note  string       `json:"note,omitempty"`
+	Score int          `json:"score"`
+	At    time.Time    `json:"at"`
+}
+
+// errNotEvaluated is returned for feedback on a job with no full
+// evaluation; a triage score isn't worth correcting.
+var errNotEvaluated = errors.New("job has no full evaluation")
+
+// recordFeedback stores a verdict against the job's latest evaluation.
+func recordFeedback(ctx context.Context, repo repository, jobID string, kind FeedbackKind, note string) (Feedback, error) {
+	job, err := repo.job(ctx, jobID)
+	if err != nil {
+		return Feedback{}, err
+	}
+	if len(job.Evaluations) == 0 || job.Evaluations[len(job.Evaluations)-1].TriageOnly {
+		return Feedback{}, fmt.Errorf("JobID %s: %w", jobID, errNotEvaluated)
+	}
+	eval := job.Evaluations[len(job.Evaluations)-1]
+	fb := Feedback{Kind: kind, Note: note, Score: eval.Score - eval.FeedbackAdjustment, At: time.Now()}
+	if err := repo.saveFeedback(ctx, jobID, fb); err != nil {
+		return Feedback{}, err
+	}
+	return fb, nil
+}
+
+// feedbackTarget is how many points the verdict says the score was off by.
+// "Good" and "bad" only move scores that disagree with them.
+func feedbackTarget(fb Feedback) float64 {
+	const goodFloor, badCeiling, nudge = 80, 30, 15
+	switch fb.Kind {
+	case FeedbackGoodMatch:
+		return math.Max(0, float64(goodFloor-fb.Score))
+	case FeedbackBadMatch:
+		return math.Min(0, float64(badCeiling-fb.Score))
+	case FeedbackTooHigh:
+		return -nudge
+	case FeedbackTooLow:
+		return nudge
+	}
+	return 0
+}
+
+// feedbackModel is a small ridge regression predicting how many points to
+// add to an evaluation from its sub-scores and resume similarity.
+type feedbackModel struct {
+	Keys    []string  // rubric criteria, in feature order after the bias
+	Weights []float64 // bias, one per key, then similarity
+	Samples int
+}
+
+const (
+	feedbackRidge         = 1.0
+	maxFeedbackAdjustment = 25
+)
+
+// feedbackFeatures scales everything to 0-1 so the ridge penalty treats
+// each feature alike. Missing sub-scores fall back to the total.
+func feedbackFeatures(e Evaluation, keys []string) []float64 {
+	raw := float64(e.Score - e.FeedbackAdjustment)
+	f := []float64{1}
+	for _, key := range keys {
+		v := raw
+		for _, s := range e.SubScores {
+			if s.Key == key && !s.Missing {
+				v = float64(s.Score)
+			}
+		}
+		f = append(f, v/100)
+	}
+	return append(f, e.Similarity)
+}
+
+// fitFeedbackModel trains on every verdict in the history. It returns nil
+// when there isn't enough feedback yet.
+func fitFeedbackModel(ix *jobIndex, rubric *Rubric) *feedbackModel {
+	var keys []string
+	for _, c := range rubric.Criteria {
+		keys = append(keys, c.Key)
+	}
+
+	var xs [][]float64
+	var ys []float64
+	ix.mu.Lock()
+	for _, job := range ix.Jobs {
+		eval := job.scoredEvaluation()
+		if eval == nil {
+			continue
+		}
+		for _, fb := range job.Feedback {
+			// Features come from the latest evaluation but the target is
+			// relative to the score the verdict was given on.
+			e := *eval
+			e.Score, e.FeedbackAdjustment = fb.Score, 0
+			xs = append(xs, feedbackFeatures(e, keys))
+			ys = append(ys, feedbackTarget(fb))
+		}
+	}
+	ix.mu.Unlock()
+
+	if len(xs) < config.Feedback.MinSamples {
+		return nil
+	}
+	w, ok := ridgeRegression(xs, ys, feedbackRidge)
+	if !ok {
+		return nil
+	}
+	return &feedbackModel{Keys: keys, Weights: w, Samples: len(xs)}
+}
+
+// adjustment is the rounded, clamped number of points to add to e.
+func (m *feedbackModel) adjustment(e Evaluation) int {
+	if m == nil {
+		return 0
+	}
+	var sum float64
+	for i, v := range feedbackFeatures(e, m.Keys) {
+		sum += m.Weights[i] * v
+	}
+	return int(math.Round(math.Max(-maxFeedbackAdjustment, math.Min(maxFeedbackAdjustment, sum))))
+}
+
+// applyFeedbackModel adds the model's adjustment to each evaluation's score.
+func applyFeedbackModel(m *feedbackModel, evals []Evaluation) {
+	if m == nil {
+		return
+	}
+	for i := range evals {
+		adj := m.adjustment(evals[i])
+		evals[i].FeedbackAdjustment = adj
+		evals[i].Score = clampScore(evals[i].Score + adj)
+	}
+}
+
+// ridgeRegression solves (XᵀX + λI)w = Xᵀy by Gaussian elimination. The bias
+// in column 0 isn't penalized.
+func ridgeRegression(xs [][]float64, ys []float64, lambda float64) ([]float64, bool) {
+	n := len(xs[0])
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	for r, x := range xs {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += x[i] * x[j]
+			}
+			a[i][n] += x[i] * ys[r]
+		}
+	}
+	for i := 1; i < n; i++ {
+		a[i][i] += lambda
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			factor := a[r][col] / a[col][col]
+			for c := col; c <= n; c++ {
+				a[r][c] -= factor * a[col][c]
+			}
+		}
+	}
+
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = a[i][n] / a[i][i]
+	}
+	return w, true
+}
+
+// PromptExample is a past evaluation and our verdict on it, shown to the
+// model as a few-shot example.
+type PromptExample struct {
+	Title   string
+	Company string
+	Score   int
+	Verdict string
+	Note    string
+}
+
+// feedbackEntry is one recorded verdict and the job it was given on.
+type feedbackEntry struct {
+	job *indexedJob
+	fb  Feedback
+}
+
+// feedbackLog lists every recorded verdict, oldest first, with verdicts
+// from the same moment ordered by job ID.
+func feedbackLog(ix *jobIndex) []feedbackEntry {
+	var entries []feedbackEntry
+	ix.mu.Lock()
+	for _, job := range ix.Jobs {
+		for _, fb := range job.Feedback {
+			entries = append(entries, feedbackEntry{job, fb})
+		}
+	}
+	ix.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.fb.At.Equal(b.fb.At) {
+			return a.fb.At.Before(b.fb.At)
+		}
+		return a.job.Description.JobID < b.job.Description.JobID
+	})
+	return entries
+}
+
+// feedbackExamples picks the most recent verdicts, one per job, preferring a
+// mix of kinds so the model sees both directions of disagreement.
+func feedbackExamples(ix *jobIndex, limit int) []PromptExample {
+	var all []feedbackEntry
+	ix.mu.Lock()
+	for _, job := range ix.Jobs {
+		if len(job.Feedback) > 0 {
+			all = append(all, feedbackEntry{job, job.Feedback[len(job.Feedback)-1]})
+		}
+	}
+	ix.mu.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].fb.At.After(all[j].fb.At) })
+
+	// The first pass takes the newest verdict of each kind, the second fills
+	// any remaining slots with the newest of the rest.
+	var picked []feedbackEntry
+	seenKinds := map[FeedbackKind]bool{}
+	pickedJobs := map[*indexedJob]bool{}
+	for pass := 0; pass < 2; pass++ {
+		for _, c := range all {
+			if len(picked) >= limit {
+				break
+			}
+			if pickedJobs[c.job] || (pass == 0 && seenKinds[c.fb.Kind]) {
+				continue
+			}
+			seenKinds[c.fb.Kind] = true
+			pickedJobs[c.job] = true
+			picked = append(picked, c)
+		}
+	}
+
+	examples := make([]PromptExample, len(picked))
+	for i, c := range picked {
+		examples[i] = PromptExample{
+			Title:   c.job.Description.JobPosition,
+			Company: c.job.Description.CompanyName,
+			Score:   c.fb.Score,
+			Verdict: feedbackLabels[c.fb.Kind],
+			Note:    c.fb.Note,
+		}
+	}
+	return examples
+}
+
+// feedbackSignature authenticates feedback links and API calls, so a guessed
+// URL can't record feedback. It is empty when feedback.secret is unset.
+func feedbackSignature(jobID string, kind FeedbackKind) string {
+	secret := config.Feedback.Secret
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(jobID + "\x00" + string(kind)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// validFeedbackSignature checks a signature from a link or API call. Without
+// FEEDBACK_SECRET nothing validates, so the web routes can't record feedback
+// at all; use the feedback command instead.
+func validFeedbackSignature(jobID string, kind FeedbackKind, sig string) bool {
+	want := feedbackSignature(jobID, kind)
+	return want != "" && hmac.Equal([]byte(want), []byte(sig))
+}
+
+// feedbackURL is the link for one verdict, under base (e.g. FEEDBACK_URL).
+func feedbackURL(base, jobID string, kind FeedbackKind) string {
+	u := strings.TrimRight(base, "/") + "/feedback/" + url.PathEscape(jobID) + "?kind=" + string(kind)
+	if sig := feedbackSignature(jobID, kind); sig != "" {
+		u += "&sig=" + sig
+	}
+	return u
+}
+
+// runFeedbackCommand records and reviews feedback from the command line.
+func runFeedbackCommand(args []string) error {
+	usage := errors.New("usage: feedback <add|list|model> ...")
+	if len(args) == 0 {
+		return usage
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("feedback add", flag.ContinueOnError)
+		note := fs.String("note", "", "why the evaluation was off")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("usage: feedback add [-note text] <jobID> <good_match|bad_match|too_high|too_low>")
+		}
+		kind, err := parseFeedbackKind(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		return withRepository(ctx, func(repo repository) error {
+			fb, err := recordFeedback(ctx, repo, fs.Arg(0), kind, *note)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Recorded %s for %s (score %d)\n", feedbackLabels[kind], fs.Arg(0), fb.Score)
+			return nil
+		})
+
+	case "list":
+		ix, err := loadStoredJobIndex(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tFEEDBACK\tSCORE\tJOB ID\tTITLE\tCOMPANY\tNOTE")
+		for _, e := range feedbackLog(ix) {
+			d := e.job.Description
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", e.fb.At.Format("2006-01-02"), e.fb.Kind, e.fb.Score, d.JobID, d.JobPosition, d.CompanyName, e.fb.Note)
+		}
+		return w.Flush()
+
+	case "model":
+		ix, err := loadStoredJobIndex(ctx)
+		if err != nil {
+			return err
+		}
+		rubric, err := loadRubric(config)
+		if err != nil {
+			return err
+		}
+		m := fitFeedbackModel(ix, rubric)
+		if m == nil {
+			fmt.Printf("Not enough feedback yet; scores are adjusted after %d verdicts.\n", config.Feedback.MinSamples)
+			return nil
+		}
+		fmt.Printf("Fitted on %d verdicts. Points added per unit of each feature:\n", m.Samples)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "bias\t%+.1f\n", m.Weights[0])
+		for i, key := range m.Keys {
+			fmt.Fprintf(w, "%s (0-1)\t%+.1f\n", key, m.Weights[i+1])
+		}
+		fmt.Fprintf(w, "similarity\t%+.1f\n", m.Weights[len(m.Weights)-1])
+		return w.Flush()
+
+	default:
+		return usage
+	}
+}
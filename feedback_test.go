@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRidgeRegressionRecoversLine(t *testing.T) {
+	// y = 10 - 20x with no noise; a tiny penalty should recover it closely.
+	var xs [][]float64
+	var ys []float64
+	for _, x := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		xs = append(xs, []float64{1, x})
+		ys = append(ys, 10-20*x)
+	}
+	w, ok := ridgeRegression(xs, ys, 1e-9)
+	if !ok || math.Abs(w[0]-10) > 1e-6 || math.Abs(w[1]+20) > 1e-6 {
+		t.Errorf("ridgeRegression = %v, %v; want [10 -20]", w, ok)
+	}
+}
+
+func TestFeedbackModelLearnsToLowerScores(t *testing.T) {
+	withConfig(t, func(c *Config) { c.Feedback.MinSamples = 3 })
+	rubric := &Rubric{Criteria: []Criterion{{Key: "skills", Name: "Skills", Weight: 100}}}
+	now := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	ix := newJobIndex()
+	for i, id := range []string{"a", "b", "c", "d"} {
+		ix.Jobs[id] = &indexedJob{
+			Description: JobDescription{JobID: id, JobPosition: "Job " + id},
+			Evaluations: []Evaluation{{JobID: id, Score: 70, SubScores: []SubScore{{Key: "skills", Score: 70}}}},
+			Feedback:    []Feedback{{Kind: FeedbackTooHigh, Score: 70, At: now.Add(time.Duration(i) * time.Hour)}},
+		}
+	}
+
+	m := fitFeedbackModel(ix, rubric)
+	if m == nil || m.Samples != 4 {
+		t.Fatalf("expected a model fitted on 4 verdicts, got %+v", m)
+	}
+	evals := []Evaluation{{Score: 70, SubScores: []SubScore{{Key: "skills", Score: 70}}}}
+	applyFeedbackModel(m, evals)
+	if evals[0].FeedbackAdjustment >= 0 || evals[0].Score != 70+evals[0].FeedbackAdjustment {
+		t.Errorf("expected a negative adjustment, got %+v", evals[0])
+	}
+
+	withConfig(t, func(c *Config) { c.Feedback.MinSamples = 10 })
+	if fitFeedbackModel(ix, rubric) != nil {
+		t.Error("expected no model below the minimum number of verdicts")
+	}
+}
+
+func TestFeedbackExamplesMixKinds(t *testing.T) {
+	now := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	kinds := []FeedbackKind{FeedbackTooHigh, FeedbackTooHigh, FeedbackTooHigh, FeedbackGoodMatch}
+	ix := newJobIndex()
+	for i, kind := range kinds {
+		id := string(rune('a' + i))
+		ix.Jobs[id] = &indexedJob{
+			Description: JobDescription{JobID: id, JobPosition: "Job " + id, CompanyName: "Co"},
+			Evaluations: []Evaluation{{JobID: id, Score: 60}},
+			Feedback:    []Feedback{{Kind: kind, Score: 60, At: now.Add(time.Duration(i) * time.Hour)}},
+		}
+	}
+
+	examples := feedbackExamples(ix, 2)
+	if len(examples) != 2 || examples[0].Title != "Job d" || examples[1].Title != "Job c" {
+		t.Errorf("expected newest good match then newest too-high, got %+v", examples)
+	}
+
+	data := samplePromptData()
+	data.Examples = examples
+	prompt, err := loadPromptTemplate(evaluatePromptName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := prompt.Render(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `Job d at Co: you scored 60/100 and I said "good match"`) {
+		t.Errorf("rendered prompt is missing the few-shot example:\n%s", out)
+	}
+}
+
+func TestFeedbackLogIsOrdered(t *testing.T) {
+	day := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	ix := testJobIndex(
+		&indexedJob{Description: JobDescription{JobID: "b"}, Feedback: []Feedback{{Kind: FeedbackGoodMatch, At: day}, {Kind: FeedbackTooLow, At: day.AddDate(0, 0, -1)}}},
+		&indexedJob{Description: JobDescription{JobID: "a"}, Feedback: []Feedback{{Kind: FeedbackBadMatch, At: day}}},
+		&indexedJob{Description: JobDescription{JobID: "c"}, Feedback: []Feedback{{Kind: FeedbackTooHigh, At: day.AddDate(0, 0, 1)}}},
+	)
+	var got []string
+	for _, e := range feedbackLog(ix) {
+		got = append(got, e.job.Description.JobID+":"+string(e.fb.Kind))
+	}
+	want := []string{"b:too_low", "a:bad_match", "b:good_match", "c:too_high"}
+	if !slices.Equal(got, want) {
+		t.Errorf("feedbackLog = %v, want %v", got, want)
+	}
+}
+
+func TestFeedbackSignature(t *testing.T) {
+	withConfig(t, func(c *Config) { c.Feedback.Secret = "" })
+	if validFeedbackSignature("1", FeedbackGoodMatch, "") {
+		t.Error("nothing should validate without a secret")
+	}
+
+	withConfig(t, func(c *Config) { c.Feedback.Secret = "s3cret" })
+	link := feedbackURL("http://localhost:8080/", "1", FeedbackGoodMatch)
+	sig := link[strings.Index(link, "sig=")+4:]
+	if !strings.HasPrefix(link, "http://localhost:8080/feedback/1?kind=good_match&sig=") {
+		t.Errorf("unexpected link %q", link)
+	}
+	if !validFeedbackSignature("1", FeedbackGoodMatch, sig) {
+		t.Error("expected signature to validate")
+	}
+	if validFeedbackSignature("1", FeedbackBadMatch, sig) || validFeedbackSignature("2", FeedbackGoodMatch, sig) {
+		t.Error("signature must be bound to the job and the verdict")
+	}
+}
+
+func TestRecordFeedback(t *testing.T) {
+	ctx := context.Background()
+	s := testStores(t)[dbSQLite]
+	seedStore(t, s,
+		&indexedJob{Description: JobDescription{JobID: "1"}, Evaluations: []Evaluation{{JobID: "1", Score: 70, FeedbackAdjustment: -5}}},
+		&indexedJob{Description: JobDescription{JobID: "2"}},
+		&indexedJob{Description: JobDescription{JobID: "3"}, Evaluations: []Evaluation{{JobID: "3", Score: 20, TriageOnly: true}}})
+
+	fb, err := recordFeedback(ctx, s, "1", FeedbackTooLow, "undersold")
+	if err != nil || fb.Score != 75 {
+		t.Fatalf("recordFeedback = %+v, %v; want the score before adjustment", fb, err)
+	}
+	if job, err := s.job(ctx, "1"); err != nil || len(job.Feedback) != 1 || job.Feedback[0].Note != "undersold" {
+		t.Errorf("stored feedback = %+v, %v", job.Feedback, err)
+	}
+	if _, err := recordFeedback(ctx, s, "2", FeedbackTooLow, ""); !errors.Is(err, errNotEvaluated) {
+		t.Errorf("feedback on an unevaluated job: %v", err)
+	}
+	if _, err := recordFeedback(ctx, s, "3", FeedbackTooLow, ""); !errors.Is(err, errNotEvaluated) {
+		t.Errorf("feedback on a triage-only job: %v", err)
+	}
+	if _, err := recordFeedback(ctx, s, "missing", FeedbackTooLow, ""); !errors.Is(err, errJobNotStored) {
+		t.Errorf("feedback on an unknown job: %v", err)
+	}
+}
+
+func TestFeedbackRoutesRequireSignature(t *testing.T) {
+	s := testStores(t)[dbSQLite]
+	seedStore(t, s, &indexedJob{
+		Description: JobDescription{JobID: "1", JobPosition: "Go Intern"},
+		Evaluations: []Evaluation{{JobID: "1", Score: 60}},
+	})
+	mux := newServeMux(s)
+	serve := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	feedbackCount := func() int {
+		job, err := s.job(context.Background(), "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(job.Feedback)
+	}
+
+	// Without a secret the web routes record nothing.
+	withConfig(t, func(c *Config) { c.Feedback.Secret = "" })
+	if rec := serve(http.MethodPost, "/feedback/1", "application/x-www-form-urlencoded", "kind=good_match"); rec.Code != http.StatusForbidden {
+		t.Errorf("unsigned form = %d", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/api/feedback/1", "application/json", `{"kind":"good_match"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("unsigned API call = %d", rec.Code)
+	}
+
+	withConfig(t, func(c *Config) { c.Feedback.Secret = "s3cret" })
+	sig := feedbackSignature("1", FeedbackGoodMatch)
+
+	// Following the link only asks for confirmation.
+	rec := serve(http.MethodGet, "/feedback/1?kind=good_match&sig="+sig, "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<form method='post'>") || feedbackCount() != 0 {
+		t.Errorf("GET = %d, %d feedback recorded: %s", rec.Code, feedbackCount(), rec.Body)
+	}
+	if rec := serve(http.MethodPost, "/feedback/1", "application/x-www-form-urlencoded", "kind=bad_match&sig="+sig); rec.Code != http.StatusForbidden {
+		t.Errorf("signature for another verdict = %d", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/feedback/1", "application/x-www-form-urlencoded", "kind=good_match&note=nice&sig="+sig); rec.Code != http.StatusOK {
+		t.Errorf("confirmed form = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPost, "/api/feedback/1", "application/json", `{"kind":"good_match","sig":"`+sig+`"}`); rec.Code != http.StatusOK {
+		t.Errorf("signed API call = %d: %s", rec.Code, rec.Body)
+	}
+	if n := feedbackCount(); n != 2 {
+		t.Errorf("recorded %d verdicts, want 2", n)
+	}
+}
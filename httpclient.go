@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpClient is used for every outbound API call. Tests swap it for one
+// backed by a cassette; http.cassette does the same from the command line.
+var httpClient = &http.Client{}
+
+// sleep is time.Sleep, replaced in tests so rate limiting and retry backoff
+// don't slow them down.
+var sleep = time.Sleep
+
+// configureHTTPClient applies http.cassette and http.debug once the
+// configuration is loaded. Recording stores real responses for later offline
+// runs; replaying serves them without touching the network. Debug dumps
+// every exchange to the log with secrets scrubbed.
+func configureHTTPClient() error {
+	var transport http.RoundTripper = http.DefaultTransport
+	if config.HTTP.Debug {
+		transport = dumpTransport{next: transport}
+	}
+	if path, mode := config.HTTP.Cassette, config.HTTP.CassetteMode; path != "" {
+		ct, err := newCassetteTransport(path, mode, transport)
+		if err != nil {
+			return err
+		}
+		slog.Info("Using HTTP cassette", "file", path, "mode", mode)
+		transport = ct
+	}
+	if transport != http.DefaultTransport {
+		httpClient = &http.Client{Transport: transport}
+	}
+	return nil
+}
+
+// scrapingDogBaseURL is the ScrapingDog API root.
+func scrapingDogBaseURL() string {
+	return strings.TrimRight(config.ScrapingDog.BaseURL, "/")
+}
+
+// ollamaChatURL is the Ollama chat endpoint.
+func ollamaChatURL() string {
+	return config.Ollama.URL
+}
+
+// scrapingDogGet sends one ScrapingDog request in its own span and counts
+// the response. The URL carries the API key, so it is never recorded.
+func scrapingDogGet(ctx context.Context, endpoint, url string, attrs ...attribute.KeyValue) (*http.Response, error) {
+	ctx, span := tracer.Start(ctx, "scrapingdog "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	res, err := httpClient.Do(req)
+	observeScrapingDog(endpoint, res)
+	if res != nil {
+		span.SetAttributes(attribute.Int("http.status_code", res.StatusCode))
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+// retryWait is the linear backoff used by the ScrapingDog retries.
+func retryWait(attempt int) time.Duration {
+	return time.Duration(attempt*2) * time.Second
+}
@@ -3,13 +3,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"html"
 	"io"
 	"math"
 	"net/http"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -42,41 +48,213 @@ type Response struct {
 	EvalDuration       int64     `json:"eval_duration"`
 }
 
-const defaultOllamaURL = "http://localhost:11434/api/chat"
-
-const systemInstruction = `You are an expert career advisor and resume evaluator. You return strict but accurate feedback with practical suggestions.`
-
 type Evaluation struct {
-	Score int
-	Text  string
+	JobID         string
+	Title         string
+	Company       string
+	ApplyLink     string
+	Model         string
+	PromptVersion string // PromptTemplate.ID() of the evaluate prompt used
+	Score         int    // weighted rubric total, or ModelScore if no sub-scores were returned
+	RawScore      int    // Score before calibration and feedback adjustment
+	Calibrated    bool   // Score was mapped onto the reference scale by a stored calibration
+	ModelScore    int    // the holistic "Fit Score" the model picked
+	SubScores     []SubScore
+	Text          string
+	Samples       int     // number of successful samples aggregated into Score
+	Spread        float64 // standard deviation of the sample scores
+	MinScore      int
+	MaxScore      int
+	LowConfidence bool       // Spread exceeded the configured threshold
+	TriageScore   int        // score from the cheap first pass, if Triaged
+	Triaged       bool       // the cheap first pass scored this job
+	TriageOnly    bool       // fell below the triage threshold and skipped full review
+	Similarity    float64    // cosine similarity of resume and job embeddings, if enabled
+	Job           Job        // normalized posting details for filtering, sorting and display
+	Skills        SkillMatch // job requirements matched against the resume's skills
+
+	FeedbackAdjustment int // points the feedback model added; already included in Score
+
+	// Usage, summed over samples.
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration
+	GenerationTime   time.Duration // Ollama's eval_duration
+	CostUSD          float64       // estimate from the price table; 0 for unpriced models
+	FormatErrors     int           // samples missing the Fit Score or a rubric criterion
 }
 
-func getJobEvaluations(jobDescs []string) error {
-	fmt.Println("🔍 Starting getJobEvaluations")
+// evalSettings holds everything shared by the evaluations in one run.
+type evalSettings struct {
+	resume        string
+	resumeSkills  []string
+	candidateName string
+	rubric        *Rubric
+	systemPrompt  *PromptTemplate
+	evalPrompt    *PromptTemplate
+	temperature   float64
+	sampling      samplingConfig
+	examples      []PromptExample
+	calibrations  calibrationSet
+	prices        priceTable
+}
+
+// evaluateSample asks one model for one evaluation and scores the response.
+func evaluateSample(ctx context.Context, s evalSettings, model, system, prompt string) (Evaluation, error) {
+	req := Request{
+		Model:       model,
+		Stream:      false,
+		Temperature: s.temperature,
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	}
 
-	resumeBytes, err := os.ReadFile("resume.txt")
+	start := time.Now()
+	resp, err := talkToOllama(ctx, ollamaChatURL(), req)
 	if err != nil {
-		return err
+		return Evaluation{}, fmt.Errorf("error talking to Ollama (%s): %w", model, err)
+	}
+	latency := time.Since(start)
+	cost, _ := s.prices.cost(model, resp.PromptEvalCount, resp.EvalCount)
+
+	cleaned := cleanResponse(resp)
+	formatErrors := 0
+	if !strings.Contains(cleaned, "Fit Score:") {
+		logStage(stageLLM).Warn("Malformed response, no Fit Score", "model", model)
+		formatErrors = 1
+	}
+	modelScore := extractScore(cleaned)
+	subScores := extractSubScores(cleaned, s.rubric)
+	score, ok := weightedScore(subScores)
+	if !ok {
+		logStage(stageLLM).Warn("No rubric sub-scores, using Fit Score", "model", model)
+		score = modelScore
+	}
+	for _, sub := range subScores {
+		if sub.Missing {
+			formatErrors = 1
+		}
+	}
+
+	return Evaluation{
+		Model:      model,
+		Score:      score,
+		RawScore:   score,
+		ModelScore: modelScore,
+
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		Latency:          latency,
+		GenerationTime:   time.Duration(resp.EvalDuration),
+		CostUSD:          cost,
+		FormatErrors:     formatErrors,
+		SubScores:        subScores,
+		Text:             cleaned,
+	}, nil
+}
+
+// loadEvalSettings reads the resume, prompts, rubric, model and sampling
+// configuration shared by every evaluation in a run.
+func loadEvalSettings() (evalSettings, error) {
+	resumeBytes, err := os.ReadFile(config.Resume.File)
+	if err != nil {
+		return evalSettings{}, err
 	}
 	resumeContent := string(resumeBytes)
 
-	outputFile := "LinkedinEvaluations.html"
+	systemPrompt, err := loadPromptTemplate(systemPromptName)
+	if err != nil {
+		return evalSettings{}, err
+	}
+	evalPrompt, err := loadPromptTemplate(evaluatePromptName)
+	if err != nil {
+		return evalSettings{}, err
+	}
+
+	rubric, err := loadRubric(config)
+	if err != nil {
+		return evalSettings{}, err
+	}
+	temperature := config.Ollama.Temperature
+
+	sampling, err := loadSamplingConfig(config)
+	if err != nil {
+		return evalSettings{}, err
+	}
+	logStage(stageLLM).Info("Evaluation settings",
+		"system_prompt", systemPrompt.ID(), "eval_prompt", evalPrompt.ID(), "temperature", temperature,
+		"models", strings.Join(sampling.Models, ","), "samples", sampling.Samples, "aggregate", sampling.Aggregate)
+
+	calibrations, err := loadCalibrations(config.Eval.CalibrationFile)
+	if err != nil {
+		return evalSettings{}, err
+	}
+
+	prices, err := loadPriceTable(config.Ollama.PriceFile)
+	if err != nil {
+		return evalSettings{}, err
+	}
+
+	return evalSettings{
+		resume:        resumeContent,
+		resumeSkills:  findSkills(resumeContent),
+		candidateName: config.Resume.CandidateName,
+		rubric:        rubric,
+		systemPrompt:  systemPrompt,
+		evalPrompt:    evalPrompt,
+		temperature:   temperature,
+		sampling:      sampling,
+		calibrations:  calibrations,
+		prices:        prices,
+	}, nil
+}
 
-	fmt.Printf("🗑️  Resetting output file: %s\n", outputFile)
+// getJobEvaluations evaluates every job, writes the report and returns the
+// new evaluations, including jobs that only went through triage. Previous
+// evaluations are only added to the report; history supplies the feedback.
+func getJobEvaluations(ctx context.Context, redisDB *redis.Client, history *jobIndex, jobDescs []JobDescription, previous []Evaluation) ([]Evaluation, error) {
+	start := time.Now()
+	settings, err := loadEvalSettings()
+	if err != nil {
+		return nil, err
+	}
+	resumeContent, rubric := settings.resume, settings.rubric
+	sortBy := config.Eval.SortBy
+
+	// Past feedback tunes both the prompt (as few-shot examples) and the
+	// final scores.
+	settings.examples = feedbackExamples(history, config.Feedback.Examples)
+	feedback := fitFeedbackModel(history, rubric)
+	if feedback != nil {
+		logStage(stageLLM).Info("Adjusting scores with feedback", "verdicts", feedback.Samples)
+	}
 
-	modelName := os.Getenv("OLLAMA_MODEL")
-	if modelName == "" {
-		modelName = "gemma3:1b" // Model preference here
+	embed, err := loadEmbedConfig(config)
+	if err != nil {
+		return nil, err
 	}
-	temperature := 0.3 // default value
-	if tStr := os.Getenv("OLLAMA_TEMP"); tStr != "" {
-		if tVal, err := strconv.ParseFloat(tStr, 64); err == nil {
-			temperature = tVal
+	var similarities map[string]float64
+	if embed.enabled() {
+		embedStart := time.Now()
+		similarities, err = jobSimilarities(ctx, redisDB, embed, resumeContent, jobDescs)
+		if err != nil {
+			logStage(stageEmbed).Warn("Skipping similarity ranking", "model", embed.Model, "err", err)
+		} else {
+			logStage(stageEmbed).Info("Computed embeddings", "model", embed.Model, "jobs", len(similarities), durationMS(embedStart))
 		}
 	}
-	fmt.Printf("🌡️ Using temperature: %.2f\n", temperature)
 
-	fmt.Printf("🤖 Using model: %s\n", modelName)
+	triage, err := loadTriageConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	var triageScores map[string]int
+	var skipped []Evaluation
+	if triage.enabled() {
+		jobDescs, triageScores, skipped = triageJobs(ctx, settings, triage, jobDescs, similarities)
+	}
 
 	const maxConcurrent = 1 // Max concurrent channels (More Threads = Better Concurrency)
 	sem := make(chan struct{}, maxConcurrent)
@@ -89,125 +267,81 @@ func getJobEvaluations(jobDescs []string) error {
 		wg.Add(1)
 		sem <- struct{}{}
 
-		go func(i int, jobDesc string) {
+		go func(i int, jobDesc JobDescription) {
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			fmt.Printf("🧠 Evaluating job #%d\n", i+1)
-
-			prompt := fmt.Sprintf(`
-			I will provide:
-			1. My resume.
-			2. A job listing.
-
-			Your task is to evaluate my exact fit for the job based strictly on the information provided.
-
-			Requirements:
-			- Be extremely detailed and realistic in your scoring.
-			- Do NOT inflate the score.
-			- Use the full range from 0 to 100.
-			- Deduct points for each missing qualification or mismatch.
-			- Provide actionable, specific suggestions, not generic tips.
-			- Return the result content in an HTML format (eg. <a> for links)
-
-			Format your response EXACTLY as follows:
-			---
-			Job Title: <title>
-
-			Company: 
-
-			Job Application Link:
-			<url>
-
-			Fit Score: <score>/100
-
-			Explanation:
-			<why this score was given — be specific and refer to the resume and job listing directly>
-
-			Suggested Resume Changes:
-			- <specific change 1>
-			- <specific change 2>
-
-			Missing Qualifications:
-			- <missing 1>
-			- <missing 2>
-			---
-
-			Here is my resume:
-			===
-			%v
-			===
-
-			Here is the job listing:
-			===
-			%v
-			===
-			`, resumeContent, jobDesc)
-
-			req := Request{
-				Model:       modelName,
-				Stream:      false,
-				Temperature: temperature,
-				Messages: []Message{
-					{Role: "system", Content: systemInstruction},
-					{Role: "user", Content: prompt},
-				},
-			}
-
-			resp, err := talkToOllama(defaultOllamaURL, req)
+			lg := logStage(stageLLM).With("job_id", jobDesc.JobID)
+			jobStart := time.Now()
+			eval, err := evaluateJob(ctx, settings, i, jobDesc)
 			if err != nil {
-				fmt.Printf("❌ Error talking to Ollama for job #%d: %v\n", i+1, err)
+				lg.Error("Evaluation failed", "err", err, durationMS(jobStart))
 				return
 			}
-
-			cleaned := cleanResponse(resp)
-			if !strings.Contains(cleaned, "Fit Score:") {
-				fmt.Printf("⚠️ Invalid or malformed response for job #%d, skipping\n", i+1)
-			}
-			score := extractScore(cleaned)
-
-			formatted := fmt.Sprintf("🔽 Job Evaluation #%d\n%s\n\n", i+1, cleaned)
+			lg.Info("Evaluated job", "model", eval.Model, "score", eval.Score, durationMS(jobStart))
+			evaluationScores.WithLabelValues(eval.Model).Observe(float64(eval.Score))
+			eval.TriageScore, eval.Triaged = triageScores[jobDesc.JobID]
+			eval.Similarity = similarities[jobDesc.JobID]
 
 			mu.Lock()
-			evaluations = append(evaluations, Evaluation{
-				Score: score,
-				Text:  formatted,
-			})
+			evaluations = append(evaluations, eval)
 			mu.Unlock()
 		}(i, jobDesc)
 	}
 
 	wg.Wait()
-
-	fmt.Println("📑 Sorting evaluations by score")
-	sorted := sortEvaluations(evaluations)
+	applyFeedbackModel(feedback, evaluations)
+
+	var earlierSkipped []Evaluation
+	for _, eval := range previous {
+		if eval.TriageOnly {
+			earlierSkipped = append(earlierSkipped, eval)
+		} else {
+			evaluations = append(evaluations, eval)
+		}
+	}
+	sorted := sortEvaluationsBy(evaluations, sortBy)
+	skipped = sortEvaluations(append(skipped, earlierSkipped...))
+	for i := range skipped {
+		if sim, ok := similarities[skipped[i].JobID]; ok {
+			skipped[i].Similarity = sim
+		}
+	}
 
 	var outputBuffer strings.Builder
 	for _, eval := range sorted {
 		outputBuffer.WriteString(eval.Text)
 	}
 
-	outputFile = "LinkedinEvaluations.html"
-	fmt.Printf("🗑️  Resetting output file: %s\n", outputFile)
+	outputFile := config.Storage.ReportFile
 	err = os.WriteFile(outputFile, []byte(outputBuffer.String()), 0644)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Printf("✅ Text evaluations saved to %s\n", outputFile)
 
 	// Also write HTML file
-	htmlFile := "LinkedinEvaluations.html"
-	err = writeHTMLFile(htmlFile, sorted)
+	htmlFile := config.Storage.ReportFile
+	err = writeHTMLFile(htmlFile, sorted, skipped, rubric, sortBy, usageFrom(ctx).summary())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	fmt.Printf("🌐 HTML evaluations saved to %s\n", htmlFile)
+	logStage(stageReport).Info("Report written", "file", htmlFile, "evaluated", len(sorted), "skipped", len(skipped), "sort_by", sortKeyOrDefault(sortBy), durationMS(start))
 
-	return nil
+	// Previous evaluations are already in the history.
+	reported := map[string]bool{}
+	for _, eval := range previous {
+		reported[eval.JobID] = true
+	}
+	var fresh []Evaluation
+	for _, eval := range append(sorted, skipped...) {
+		if !reported[eval.JobID] {
+			fresh = append(fresh, eval)
+		}
+	}
+	return fresh, nil
 }
 
 func extractScore(text string) int {
-	fmt.Println("📈 Extracting score from text")
 	re := regexp.MustCompile(`(?i)Fit Score:\s*(\d+(?:\.\d+)?)/100`)
 	matches := re.FindStringSubmatch(text)
 	if len(matches) >= 2 {
@@ -216,36 +350,84 @@ func extractScore(text string) int {
 			return int(math.Round(f))
 		}
 	}
-	fmt.Println("⚠️  Score not found or invalid, defaulting to 0")
+	logStage(stageLLM).Debug("Score not found or invalid, defaulting to 0")
 	return 0
 }
 
 func sortEvaluations(evals []Evaluation) []Evaluation {
-	fmt.Println("🔃 Sorting evaluations in descending order")
+	return sortEvaluationsBy(evals, "score")
+}
+
+// sortEvaluationsBy orders evaluations by the weighted total, a single rubric
+// criterion, "posted" for newest first or "salary" for best paid first,
+// breaking ties on the total and then on posting date.
+func sortEvaluationsBy(evals []Evaluation, key string) []Evaluation {
 	sort.SliceStable(evals, func(i, j int) bool {
-		return evals[i].Score > evals[j].Score
+		switch key {
+		case "posted":
+			if a, b := evals[i].Job.PostedAt, evals[j].Job.PostedAt; !a.Equal(b) {
+				return a.After(b)
+			}
+		case "salary":
+			if a, b := evals[i].Job.annualMaxUSD(), evals[j].Job.annualMaxUSD(); a != b {
+				return a > b
+			}
+		}
+		a, b := evals[i].criterionScore(key), evals[j].criterionScore(key)
+		if a != b {
+			return a > b
+		}
+		if evals[i].Score != evals[j].Score {
+			return evals[i].Score > evals[j].Score
+		}
+		return evals[i].Job.PostedAt.After(evals[j].Job.PostedAt)
 	})
 	return evals
 }
 
-func talkToOllama(url string, ollamaReq Request) (*Response, error) {
-	fmt.Println("📤 Sending request to Ollama")
+func sortKeyOrDefault(key string) string {
+	if key == "" {
+		return "score"
+	}
+	return key
+}
+
+func talkToOllama(ctx context.Context, url string, ollamaReq Request) (ollamaResp *Response, err error) {
+	ctx, span := tracer.Start(ctx, "ollama chat", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("model", ollamaReq.Model), attribute.Int("messages", len(ollamaReq.Messages))))
+	defer func() {
+		if ollamaResp != nil {
+			span.SetAttributes(
+				attribute.Int("tokens.prompt", ollamaResp.PromptEvalCount),
+				attribute.Int("tokens.completion", ollamaResp.EvalCount),
+				attribute.Int64("ollama.total_duration_ms", ollamaResp.TotalDuration/1e6),
+				attribute.Int64("ollama.load_duration_ms", int64(ollamaResp.LoadDuration)/1e6),
+				attribute.Int64("ollama.prompt_eval_duration_ms", int64(ollamaResp.PromptEvalDuration)/1e6),
+				attribute.Int64("ollama.eval_duration_ms", ollamaResp.EvalDuration/1e6),
+			)
+		}
+		endSpan(span, err)
+	}()
+	lg := logStage(stageLLM).With("model", ollamaReq.Model)
 	reqJSON, err := json.Marshal(&ollamaReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	fmt.Printf("📦 Marshalled JSON request size: %d bytes\n", len(reqJSON))
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(reqJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqJSON))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := http.Client{}
-	res, err := client.Do(req)
+	start := time.Now()
+	defer func() {
+		observeLLM(ollamaReq.Model, start, ollamaResp)
+		usageFrom(ctx).record(ollamaReq.Model, ollamaResp, time.Since(start))
+	}()
+	res, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to send HTTP request: %w", redactErr(err))
 	}
 	defer res.Body.Close()
 
@@ -253,25 +435,22 @@ func talkToOllama(url string, ollamaReq Request) (*Response, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
-	fmt.Printf("📥 Received response status: %s\n", res.Status)
-	fmt.Printf("📥 Response body size: %d bytes\n", len(bodyBytes))
+	lg.Debug("Ollama response", "status", res.StatusCode, "request_bytes", len(reqJSON), "response_bytes", len(bodyBytes), durationMS(start))
 
 	if res.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status: %s, body: %s", res.Status, string(bodyBytes))
 	}
 
-	ollamaResp := Response{}
-	err = json.Unmarshal(bodyBytes, &ollamaResp)
+	var decoded Response
+	err = json.Unmarshal(bodyBytes, &decoded)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode response JSON: %w", err)
 	}
-	fmt.Println("✅ Successfully unmarshalled response JSON")
-
-	return &ollamaResp, nil
+	ollamaResp = &decoded
+	return ollamaResp, nil
 }
 
 func cleanResponse(resp *Response) string {
-	fmt.Println("🧹 Cleaning response")
 	clean := resp.Message.Content
 
 	// Remove <think> tags
@@ -283,22 +462,59 @@ func cleanResponse(resp *Response) string {
 	clean = linkPattern.ReplaceAllString(clean, "$1")
 
 	clean = strings.TrimSpace(clean)
-	fmt.Println("🔎 Cleaned response content:")
 
 	return clean
 }
 
-func writeHTMLFile(filename string, evaluations []Evaluation) error {
-	fmt.Println("🖨️ Generating HTML output")
-
+func writeHTMLFile(filename string, evaluations, skipped []Evaluation, rubric *Rubric, sortBy string, usage []llmUsage) error {
 	var sb strings.Builder
 	sb.WriteString("<!DOCTYPE html><html><head><meta charset=\"UTF-8\"><title>Job Evaluations</title>")
-	sb.WriteString("<style>body{font-family:sans-serif;padding:20px;} .eval{margin-bottom:40px;padding:20px;border:1px solid #ccc;border-radius:10px;} h2{margin-top:0;} a{color:#0645AD;}</style>")
+	sb.WriteString("<style>body{font-family:sans-serif;padding:20px;} .eval{margin-bottom:40px;padding:20px;border:1px solid #ccc;border-radius:10px;} h2{margin-top:0;} .meta{color:#666;font-size:0.85em;} a{color:#0645AD;} .breakdown{border-collapse:collapse;margin-bottom:15px;} .breakdown td,.breakdown th{border:1px solid #ddd;padding:4px 8px;text-align:left;vertical-align:top;} .missing{color:#b00;} .low-confidence{background:#fff3cd;border:1px solid #e0c060;padding:2px 6px;border-radius:4px;} .chip{display:inline-block;margin:2px;padding:1px 8px;border-radius:10px;font-size:0.85em;} .chip.matched{background:#d4edda;} .chip.missing-required{background:#f8d7da;} .chip.missing-preferred{background:#fff3cd;}</style>")
 	sb.WriteString("</head><body><h1>Job Fit Evaluations</h1>")
 
+	// Sorting happens client-side on data attributes so the emailed report
+	// can be re-ranked on any criterion without re-running the pipeline.
+	sb.WriteString("<p>Sort by: <select id='sort-by' onchange='sortEvals(this.value)'>")
+	sb.WriteString(sortOption("score", "Weighted Score", sortBy))
+	for _, c := range rubric.Criteria {
+		sb.WriteString(sortOption(c.Key, c.Name, sortBy))
+	}
+	sb.WriteString(sortOption("posted", "Newest", sortBy))
+	sb.WriteString(sortOption("salary", "Salary", sortBy))
+	sb.WriteString("</select></p><div id='evals'>")
+
 	for i, eval := range evaluations {
-		sb.WriteString("<div class='eval'>")
+		sb.WriteString(fmt.Sprintf("<div class='eval' data-score='%d' data-posted='%d' data-salary='%.0f'", eval.Score, eval.Job.PostedAt.Unix(), eval.Job.annualMaxUSD()))
+		for _, s := range eval.SubScores {
+			sb.WriteString(fmt.Sprintf(" data-%s='%d'", html.EscapeString(s.Key), s.Score))
+		}
+		sb.WriteString(">")
 		sb.WriteString(fmt.Sprintf("<h2>Job Evaluation #%d</h2>", i+1))
+		sb.WriteString(fmt.Sprintf("<p class='meta'>Model: %s &middot; Prompt: %s", html.EscapeString(eval.Model), html.EscapeString(eval.PromptVersion)))
+		if eval.Triaged {
+			sb.WriteString(fmt.Sprintf(" &middot; Triage: %d/100", eval.TriageScore))
+		}
+		if eval.Similarity != 0 {
+			sb.WriteString(fmt.Sprintf(" &middot; Resume similarity: %.2f", eval.Similarity))
+		}
+		if eval.Calibrated {
+			sb.WriteString(fmt.Sprintf(" &middot; Calibrated from raw %d", eval.RawScore))
+		}
+		if eval.FeedbackAdjustment != 0 {
+			sb.WriteString(fmt.Sprintf(" &middot; Feedback adjustment: %+d", eval.FeedbackAdjustment))
+		}
+		if tokens := eval.PromptTokens + eval.CompletionTokens; tokens > 0 {
+			sb.WriteString(fmt.Sprintf(" &middot; %d tokens", tokens))
+		}
+		if eval.CostUSD > 0 {
+			sb.WriteString(" &middot; " + formatCost(llmUsage{CostUSD: eval.CostUSD, Priced: true}))
+		}
+		sb.WriteString("</p>")
+		sb.WriteString(feedbackLinksHTML(eval.JobID))
+		sb.WriteString(jobDetailsHTML(eval.Job))
+		sb.WriteString(skillsHTML(eval))
+		sb.WriteString(scoreBreakdownHTML(eval))
+		sb.WriteString(confidenceHTML(eval))
 
 		// Convert URLs to clickable links
 		htmlContent := convertTextToHTML(eval.Text)
@@ -306,9 +522,179 @@ func writeHTMLFile(filename string, evaluations []Evaluation) error {
 		sb.WriteString("</div>")
 	}
 
+	sb.WriteString("</div>")
+	sb.WriteString(triagedOutHTML(skipped))
+	sb.WriteString(skillGapHTML(analyzeSkillGaps(skillGapSamplesFromEvaluations(append(slices.Clone(evaluations), skipped...))), 10))
+	sb.WriteString(usageFooterHTML(usage))
+	sb.WriteString(`<script>
+function sortEvals(key) {
+  var list = document.getElementById('evals');
+  var items = Array.prototype.slice.call(list.children);
+  items.sort(function (a, b) {
+    var d = Number(b.dataset[key] || -1) - Number(a.dataset[key] || -1);
+    return d !== 0 ? d : Number(b.dataset.score) - Number(a.dataset.score);
+  });
+  items.forEach(function (el) { list.appendChild(el); });
+}
+</script>`)
 	sb.WriteString("</body></html>")
 	return os.WriteFile(filename, []byte(sb.String()), 0644)
 }
+
+// jobDetailsHTML shows the normalized posting fields.
+func jobDetailsHTML(job Job) string {
+	var parts []string
+	if !job.PostedAt.IsZero() {
+		parts = append(parts, "Posted "+job.PostedAt.Format("Jan 2, 2006"))
+	}
+	if job.Location.Raw != "" || job.Location.Workplace != WorkplaceUnknown {
+		parts = append(parts, job.Location.String())
+	}
+	if job.Seniority != "" && job.Seniority != SeniorityUnknown {
+		parts = append(parts, string(job.Seniority))
+	}
+	if job.EmploymentType != "" && job.EmploymentType != EmploymentUnknown {
+		parts = append(parts, string(job.EmploymentType))
+	}
+	if len(job.Industries) > 0 {
+		parts = append(parts, strings.Join(job.Industries, ", "))
+	}
+	if job.Compensation != nil {
+		parts = append(parts, "💰 "+job.Compensation.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "<p class='meta'>" + html.EscapeString(strings.Join(parts, " · ")) + "</p>"
+}
+
+// feedbackLinksHTML links each verdict to the feedback endpoint when
+// feedback.url points at a running `serve`.
+func feedbackLinksHTML(jobID string) string {
+	base := config.Feedback.URL
+	if base == "" || jobID == "" {
+		return ""
+	}
+	links := make([]string, len(allFeedbackKinds))
+	for i, kind := range allFeedbackKinds {
+		links[i] = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(feedbackURL(base, jobID, kind)), feedbackLabels[kind])
+	}
+	return "<p class='meta'>Feedback: " + strings.Join(links, " &middot; ") + "</p>"
+}
+
+// skillsHTML renders the job's skills as chips: green for skills on the
+// resume, red for missing requirements, amber for missing nice-to-haves.
+func skillsHTML(eval Evaluation) string {
+	m := eval.Skills
+	if len(m.Matched)+len(m.MissingRequired)+len(m.MissingPreferred) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<p class='skills'>")
+	for _, s := range m.Matched {
+		sb.WriteString("<span class='chip matched'>✓ " + html.EscapeString(s) + "</span>")
+	}
+	for _, s := range m.MissingRequired {
+		sb.WriteString("<span class='chip missing-required'>✗ " + html.EscapeString(s) + "</span>")
+	}
+	for _, s := range m.MissingPreferred {
+		sb.WriteString("<span class='chip missing-preferred'>○ " + html.EscapeString(s) + "</span>")
+	}
+	sb.WriteString("</p>")
+
+	req := eval.Job.Requirements
+	var extra []string
+	if req.MinYearsExperience > 0 {
+		extra = append(extra, fmt.Sprintf("%d+ years experience", req.MinYearsExperience))
+	}
+	if req.Degree != "" {
+		extra = append(extra, degreeLabels[req.Degree])
+	}
+	if len(req.Certifications) > 0 {
+		extra = append(extra, strings.Join(req.Certifications, ", "))
+	}
+	if len(extra) > 0 {
+		sb.WriteString("<p class='meta'>Requires: " + html.EscapeString(strings.Join(extra, " · ")) + "</p>")
+	}
+	return sb.String()
+}
+
+// triagedOutHTML lists the jobs the triage model filtered out, so nothing
+// fetched disappears from the report without a trace.
+func triagedOutHTML(skipped []Evaluation) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<h2>Below Triage Threshold (%d)</h2><table class='breakdown'><tr><th>Triage Score</th><th>Similarity</th><th>Job</th><th>Company</th><th>Salary</th></tr>", len(skipped)))
+	for _, eval := range skipped {
+		title := html.EscapeString(eval.Title)
+		if eval.ApplyLink != "" {
+			title = fmt.Sprintf("<a href=\"%s\" target=\"_blank\">%s</a>", html.EscapeString(eval.ApplyLink), title)
+		}
+		sim := "–"
+		if eval.Similarity != 0 {
+			sim = fmt.Sprintf("%.2f", eval.Similarity)
+		}
+		salary := "–"
+		if eval.Job.Compensation != nil {
+			salary = html.EscapeString(eval.Job.Compensation.String())
+		}
+		sb.WriteString(fmt.Sprintf("<tr><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>", eval.TriageScore, sim, title, html.EscapeString(eval.Company), salary))
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
+
+// confidenceHTML summarises the sample spread when more than one sample was taken.
+func confidenceHTML(eval Evaluation) string {
+	if eval.Samples < 2 {
+		return ""
+	}
+	flag := ""
+	if eval.LowConfidence {
+		flag = " <span class='low-confidence'>⚠️ low confidence</span>"
+	}
+	return fmt.Sprintf("<p class='meta'>Aggregated from %d samples: range %d–%d, std dev %.1f%s</p>",
+		eval.Samples, eval.MinScore, eval.MaxScore, eval.Spread, flag)
+}
+
+func sortOption(key, label, selected string) string {
+	attr := ""
+	if key == sortKeyOrDefault(selected) {
+		attr = " selected"
+	}
+	return fmt.Sprintf("<option value='%s'%s>%s</option>", html.EscapeString(key), attr, html.EscapeString(label))
+}
+
+// scoreBreakdownHTML renders the weighted total and per-criterion sub-scores.
+func scoreBreakdownHTML(eval Evaluation) string {
+	if len(eval.SubScores) == 0 {
+		return fmt.Sprintf("<p><strong>Score: %d/100</strong></p>", eval.Score)
+	}
+
+	var total float64
+	for _, s := range eval.SubScores {
+		total += s.Weight
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<p><strong>Weighted Score: %d/100</strong> (model's holistic score: %d/100)</p>", eval.Score, eval.ModelScore))
+	sb.WriteString("<table class='breakdown'><tr><th>Criterion</th><th>Weight</th><th>Score</th><th>Justification</th></tr>")
+	for _, s := range eval.SubScores {
+		justification := html.EscapeString(s.Justification)
+		if s.Missing {
+			justification = "<span class='missing'>not returned by model (scored 0)</span>"
+		}
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%.0f%%</td><td>%d</td><td>%s</td></tr>",
+			html.EscapeString(s.Name), s.Weight/total*100, s.Score, justification))
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
+
 func convertTextToHTML(text string) string {
 	// Escape HTML special chars
 	html := strings.ReplaceAll(text, "&", "&amp;")
@@ -326,7 +712,6 @@ func convertTextToHTML(text string) string {
 }
 
 func appendToFile(filename string, content string) error {
-	fmt.Printf("📝 Appending to file: %s\n", filename)
 	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
@@ -338,6 +723,5 @@ func appendToFile(filename string, content string) error {
 	if _, err := f.WriteString(entry); err != nil {
 		return err
 	}
-	fmt.Println("✅ Write successful")
 	return nil
 }
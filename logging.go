@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Pipeline stages, used as the "stage" field so one part of a run can be
+// pulled out of the logs.
+const (
+	stageFetch  = "fetch"
+	stageCache  = "cache"
+	stageIndex  = "index"
+	stageStore  = "store"
+	stageEmbed  = "embed"
+	stageTriage = "triage"
+	stageLLM    = "llm"
+	stageReport = "report"
+	stageEmail  = "email"
+)
+
+// logStage is the default logger with the stage field set. It reads the
+// default at call time, so it picks up the run_id set by startRunLogging.
+func logStage(stage string) *slog.Logger {
+	return slog.Default().With("stage", stage)
+}
+
+// durationMS is the duration_ms field for work that began at start.
+func durationMS(start time.Time) slog.Attr {
+	return slog.Int64("duration_ms", time.Since(start).Milliseconds())
+}
+
+// newLogHandler writes level and above to w as text or JSON, scrubbing
+// secrets from every record.
+func newLogHandler(w io.Writer, level, format string) (slog.Handler, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("log.level must be debug, info, warn or error, got %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	w = redactingWriter{w: w}
+	switch strings.ToLower(format) {
+	case "text", "":
+		return slog.NewTextHandler(w, opts), nil
+	case "json":
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("log.format must be text or json, got %q", format)
+	}
+}
+
+// setupLogging makes the configured handler the default for slog and for
+// the standard log package.
+func setupLogging(level, format string) error {
+	h, err := newLogHandler(os.Stderr, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// startRunLogging tags every later log entry with runID and returns a
+// function that removes the tag again.
+func startRunLogging(runID string) func() {
+	prev := slog.Default()
+	slog.SetDefault(prev.With("run_id", runID))
+	return func() { slog.SetDefault(prev) }
+}
+
+func newRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102T150405")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogHandlerJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := newLogHandler(&buf, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := slog.Default()
+	slog.SetDefault(slog.New(h))
+	t.Cleanup(func() { slog.SetDefault(old) })
+
+	done := startRunLogging(newRunID())
+	logStage(stageFetch).Info("Fetched job description", "job_id", "42", "attempt", 2, durationMS(time.Now().Add(-1500*time.Millisecond)))
+	done()
+	slog.Info("after the run")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["stage"] != "fetch" || entry["job_id"] != "42" || entry["attempt"] != 2.0 || entry["level"] != "INFO" {
+		t.Errorf("entry = %v", entry)
+	}
+	if id, _ := entry["run_id"].(string); len(id) != 12 {
+		t.Errorf("run_id = %v", entry["run_id"])
+	}
+	if ms, _ := entry["duration_ms"].(float64); ms < 1500 {
+		t.Errorf("duration_ms = %v", entry["duration_ms"])
+	}
+	if strings.Contains(lines[1], "run_id") {
+		t.Errorf("run_id outlived the run: %s", lines[1])
+	}
+}
+
+func TestLogHandlerLevelAndRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := newLogHandler(&buf, "WARN", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg := slog.New(h)
+	lg.Info("hidden")
+	lg.Warn("request failed", "err", "Get https://api.example.com/x?api_key=abc123: timeout")
+	if strings.Contains(buf.String(), "hidden") {
+		t.Errorf("info logged at warn level:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "abc123") || !strings.Contains(buf.String(), "level=WARN") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+
+	for _, bad := range [][2]string{{"loud", "text"}, {"info", "xml"}} {
+		if _, err := newLogHandler(&buf, bad[0], bad[1]); err == nil {
+			t.Errorf("newLogHandler(%q, %q) succeeded", bad[0], bad[1])
+		}
+	}
+}
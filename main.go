@@ -6,23 +6,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
 
-const (
-	maxConcurrentRequests = 1               // Controls concurrency
-	rateLimitDelay        = 2 * time.Second // Delay between requests
-	maxRetries            = 5
-)
-
 var osOpen = os.Open // default to actual os.Open
 
 type JobListing struct {
@@ -33,23 +29,29 @@ type JobListing struct {
 	CompanyProfile string `json:"company_profile"`
 	JobLocation    string `json:"job_location"`
 	JobPostingDate string `json:"job_posting_date"`
+	Profile        string `json:"profile,omitempty"` // search profile that found the listing; not part of the API response
 }
 
 type JobDescription struct {
-	JobPosition       string       `json:"job_position"`
-	JobLocation       string       `json:"job_location"`
-	CompanyName       string       `json:"company_name"`
-	CompanyLinkedInID string       `json:"company_linkedin_id"`
-	JobPostingTime    string       `json:"job_posting_time"`
-	JobDescription    string       `json:"job_description"`
-	SeniorityLevel    string       `json:"Seniority_level"`
-	EmploymentType    string       `json:"Employment_type"`
-	JobFunction       string       `json:"Job_function"`
-	Industries        string       `json:"Industries"`
-	JobApplyLink      string       `json:"job_apply_link"`
-	RecruiterDetails  []Recruiter  `json:"recruiter_details"`
-	SimilarJobs       []SimilarJob `json:"similar_jobs"`
-	PeopleAlsoViewed  []SimilarJob `json:"people_also_viewed"`
+	JobID             string        `json:"job_id"` // copied from the JobListing; not part of the API response
+	JobPosition       string        `json:"job_position"`
+	JobLocation       string        `json:"job_location"`
+	CompanyName       string        `json:"company_name"`
+	CompanyLinkedInID string        `json:"company_linkedin_id"`
+	JobPostingTime    string        `json:"job_posting_time"`
+	JobDescription    string        `json:"job_description"`
+	SeniorityLevel    string        `json:"Seniority_level"`
+	EmploymentType    string        `json:"Employment_type"`
+	JobFunction       string        `json:"Job_function"`
+	Industries        string        `json:"Industries"`
+	JobApplyLink      string        `json:"job_apply_link"`
+	RecruiterDetails  []Recruiter   `json:"recruiter_details"`
+	SimilarJobs       []SimilarJob  `json:"similar_jobs"`
+	PeopleAlsoViewed  []SimilarJob  `json:"people_also_viewed"`
+	Profile           string        `json:"profile,omitempty"`        // copied from the JobListing
+	FetchedAt         time.Time     `json:"fetched_at"`               // when we called the API; anchors relative posting times
+	Compensation      *Compensation `json:"compensation,omitempty"`   // set by the LLM salary fallback when the regex finds nothing
+	SalaryChecked     bool          `json:"salary_checked,omitempty"` // the LLM salary fallback has answered for this description
 }
 
 type Recruiter struct {
@@ -66,59 +68,222 @@ type SimilarJob struct {
 }
 
 func main() {
-	log.Println("Starting main function...")
+	// Redact from the start; the configured level and format come later.
+	setupLogging("info", "text")
 
-	err := godotenv.Load()
+	global, args := splitGlobalFlags(os.Args[1:])
+	src, err := parseGlobalFlags(global)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal(err)
 	}
-	log.Println(".env file loaded successfully")
+	cfg, err := loadConfig(src)
+	if err != nil {
+		// config show is how you debug a bad config, so let it run.
+		if len(args) == 0 || args[0] != "config" {
+			fatal(err)
+		}
+		slog.Warn("Invalid configuration", "err", err)
+	}
+	config = cfg
+	if err := setupLogging(config.Log.Level, config.Log.Format); err != nil {
+		slog.Warn("Keeping default logging", "err", err)
+	}
+	slog.Debug("Configuration loaded")
 
-	ctx := context.Background()
-	redisAddr := os.Getenv("REDIS_ADDR")
-	redisDB := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: "", // No password set
-		DB:       0,  // Use default DB
-		Protocol: 2,  // Connection protocol
-	})
+	if err := configureHTTPClient(); err != nil {
+		fatal(err)
+	}
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		fatal(err)
+	}
 
-	var jobListings []JobListing
+	switch {
+	case len(args) > 0 && strings.HasPrefix(args[0], "-"):
+		// Flags without a command, e.g. `linkedin-job-scout --offline`.
+		err = runCommand("run", args)
+	case len(args) > 0:
+		err = runCommand(args[0], args[1:])
+	default:
+		err = runPipeline(pipelineOptions{})
+	}
+	// Flush spans before exiting; fatal skips deferred calls.
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		slog.Warn("Failed to flush traces", "err", shutdownErr)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// fatal logs err and exits, like log.Fatal.
+func fatal(err error) {
+	slog.Error("Fatal error", "err", err)
+	os.Exit(1)
+}
 
-	jobListings, err = getJobListings()
+// runPipeline fetches listings, resolves their descriptions, evaluates them
+// against the resume and emails the resulting report. Offline, it works from
+// the cache and history instead, evaluates only jobs without an evaluation
+// and leaves the report on disk.
+func runPipeline(opts pipelineOptions) (err error) {
+	runID := newRunID()
+	defer startRunLogging(runID)()
+	start := time.Now()
+	defer func() { observeRun(start, err) }()
+	slog.Info("Starting run", "offline", opts.Offline)
+
+	ctx, span := tracer.Start(context.Background(), "run", trace.WithAttributes(
+		attribute.String("run_id", runID), attribute.Bool("offline", opts.Offline)))
+	defer func() { endSpan(span, err) }()
+	redisDB := newRedisClient()
+	defer redisDB.Close()
+
+	prices, err := loadPriceTable(config.Ollama.PriceFile)
 	if err != nil {
-		log.Fatalf("Error in getJobListings: %v", err)
+		return err
 	}
+	usage := newUsageTracker(prices)
+	ctx = withUsageTracker(ctx, usage)
 
-	log.Printf("Loaded %d job listings from API\n", len(jobListings))
-	log.Println("Processing job listings...")
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer repo.close()
+	index, err := loadJobIndex(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	var jobDescriptions []JobDescription
+	if opts.Offline {
+		jobDescriptions, err = offlineJobDescriptions(ctx, redisDB, repo, index, opts.Since)
+		if err != nil {
+			return err
+		}
+	} else {
+		jobListings, err := getJobListings(ctx)
+		if err != nil {
+			return fmt.Errorf("Error in getJobListings: %w (use --offline to run from cached jobs)", err)
+		}
+		if err := repo.saveListings(ctx, jobListings, time.Now()); err != nil {
+			return err
+		}
 
-	jobDescriptions := processJobListings(ctx, redisDB, jobListings)
-	log.Printf("Received %d job descriptions\n", len(jobDescriptions))
-	err = getJobEvaluations(jobDescriptions)
+		jobDescriptions = processJobListings(ctx, redisDB, jobListings)
+		logStage(stageFetch).Info("Fetched job descriptions", "listings", len(jobListings), "descriptions", len(jobDescriptions))
+	}
+
+	filters, err := loadJobFilters(config)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	err = sendEvaluationsEmail()
+
+	if opts.Offline {
+		// Stored jobs were enriched and indexed when they were fetched.
+		jobDescriptions = filterJobDescriptions(filterHandledJobs(index, jobDescriptions), filters)
+	} else {
+		if err := enrichCompensation(ctx, redisDB, index, jobDescriptions); err != nil {
+			return err
+		}
+		if err := indexJobDescriptions(ctx, redisDB, repo, index, jobDescriptions); err != nil {
+			return err
+		}
+
+		before := len(jobDescriptions)
+		jobDescriptions = filterHandledJobs(index, jobDescriptions)
+		if skipped := before - len(jobDescriptions); skipped > 0 {
+			slog.Info("Skipping jobs already handled in the application tracker", "jobs", skipped)
+		}
+		jobDescriptions = filterJobDescriptions(jobDescriptions, filters)
+	}
+	slog.Info("Filtered jobs", "jobs", len(jobDescriptions))
+
+	var previous []Evaluation
+	if opts.Offline {
+		jobDescriptions, previous = splitEvaluated(index, jobDescriptions)
+		slog.Info("Offline: split evaluated jobs", "pending", len(jobDescriptions), "evaluated", len(previous))
+	}
+
+	evaluations, err := getJobEvaluations(ctx, redisDB, index, jobDescriptions, previous)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if err := repo.saveEvaluations(ctx, runID, evaluations, time.Now()); err != nil {
+		return err
+	}
+	run := runRecord{
+		ID:          runID,
+		StartedAt:   start,
+		FinishedAt:  time.Now(),
+		Offline:     opts.Offline,
+		Jobs:        len(jobDescriptions),
+		Evaluations: len(evaluations),
+		Usage:       usage.summary(),
+	}
+	if err := repo.saveRun(ctx, run); err != nil {
+		return err
+	}
+	logUsage(run.Usage)
+
+	if opts.Offline {
+		slog.Info("Offline: report written, not emailing", "file", config.Storage.ReportFile, durationMS(start))
+		return nil
+	}
+	if err := sendEvaluationsEmail(ctx); err != nil {
+		return err
+	}
+	slog.Info("Run finished", "evaluations", len(evaluations), durationMS(start))
+	return nil
+}
+
+func newRedisClient() *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Redis.Addr,
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
+		Protocol: 2, // Connection protocol
+	})
+	client.AddHook(redisTracingHook{})
+	return client
+}
+
+// searchField is the position searched for.
+func searchField() string {
+	return config.Search.Field
+}
+
+// searchProfile names the search in job history and trend reports. It
+// defaults to the search field.
+func searchProfile() string {
+	if config.Search.Profile != "" {
+		return config.Search.Profile
 	}
+	return searchField()
 }
 
-func getJobListings() ([]JobListing, error) {
-	log.Println("Fetching job listings from API...")
+func getJobListings(ctx context.Context) (listings []JobListing, err error) {
+	ctx, span := tracer.Start(ctx, "fetch listings")
+	defer func() {
+		span.SetAttributes(attribute.Int("listings", len(listings)))
+		endSpan(span, err)
+	}()
+	lg := logStage(stageFetch)
 	var allJobListings []JobListing
 
-	apiKey := os.Getenv("SCRAPINGDOG_API_KEY")
+	apiKey := config.ScrapingDog.APIKey
 	if apiKey == "" {
-		return nil, errors.New("No API Key set in .env")
+		return nil, errors.New("no ScrapingDog API key set (scrapingdog.api_key or SCRAPINGDOG_API_KEY)")
 	}
-	geoID := os.Getenv("GEO_ID")
-
-	field := url.QueryEscape("Software Engineer Intern") // Position Searching For
-	location := url.QueryEscape("")                      // Location Name (doesn't affect query, but geoid does)
-	geoid := geoID                                       // Location ID (Set in .env)
-	sortBy := "day"                                      // Last 24 Hours
+	geoID := config.Search.GeoID
+	profile := searchProfile()
+	span.SetAttributes(attribute.String("profile", profile))
+
+	field := url.QueryEscape(searchField()) // Position Searching For
+	location := url.QueryEscape("")         // Location Name (doesn't affect query, but geoid does)
+	geoid := geoID                          // Location ID (Set in .env)
+	sortBy := "day"                         // Last 24 Hours
 	jobType := ""
 	expLevel := ""
 	workType := ""
@@ -128,33 +293,26 @@ func getJobListings() ([]JobListing, error) {
 	page := 1
 
 	for {
-		log.Printf("Requesting page %d from API\n", page)
+		lg.Debug("Requesting listings page", "page", page)
 		url := fmt.Sprintf(
-			"https://api.scrapingdog.com/linkedinjobs?api_key=%s&field=%s&geoid=%s&location=%s&page=%d&sort_by=%s&job_type=%s&exp_level=%s&work_type=%s&filter_by_company=%s",
-			apiKey, field, geoid, location, page, sortBy, jobType, expLevel, workType, filterByCompany,
+			"%s/linkedinjobs?api_key=%s&field=%s&geoid=%s&location=%s&page=%d&sort_by=%s&job_type=%s&exp_level=%s&work_type=%s&filter_by_company=%s",
+			scrapingDogBaseURL(), apiKey, field, geoid, location, page, sortBy, jobType, expLevel, workType, filterByCompany,
 		)
 
-		res, err := http.Get(url)
+		pageListings, err := getListingPage(ctx, url, page)
 		if err != nil {
-			log.Printf("Error fetching page %d: %v\n", page, err)
 			return nil, err
 		}
-		defer res.Body.Close()
 
-		var pageListings []JobListing
-		decoder := json.NewDecoder(res.Body)
-		err = decoder.Decode(&pageListings)
-		if err != nil {
-			log.Printf("Error decoding page %d: %v\n", page, err)
-			return nil, err
+		for i := range pageListings {
+			pageListings[i].Profile = profile
 		}
-
-		log.Printf("Fetched %d listings from page %d\n", len(pageListings), page)
+		lg.Info("Fetched listings page", "page", page, "listings", len(pageListings))
+		listingsFetched.WithLabelValues(profile).Add(float64(len(pageListings)))
 		allJobListings = append(allJobListings, pageListings...)
 
 		if len(pageListings) < pageSize {
-			log.Println("Less than 10 listings returned — ending pagination")
-			break
+			break // a short page is the last one
 		}
 
 		page++
@@ -163,68 +321,114 @@ func getJobListings() ([]JobListing, error) {
 	return allJobListings, nil
 }
 
-func getJobDescriptionWithRetry(ctx context.Context, redisDB *redis.Client, job JobListing) (JobDescription, error) {
-	var desc JobDescription
-	var err error
+// getListingPage fetches one page of listings, retrying while rate limited.
+func getListingPage(ctx context.Context, url string, page int) ([]JobListing, error) {
+	lg := logStage(stageFetch).With("page", page)
+	for attempt := 1; attempt <= config.ScrapingDog.MaxRetries; attempt++ {
+		start := time.Now()
+		res, err := scrapingDogGet(ctx, "listings", url, attribute.Int("page", page), attribute.Int("attempt", attempt))
+		if err != nil {
+			err = redactErr(err) // the URL carries the API key
+			lg.Error("Listings request failed", "attempt", attempt, "err", err)
+			return nil, err
+		}
+		bodyBytes, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+
+		if res.StatusCode == http.StatusTooManyRequests {
+			wait := retryWait(attempt)
+			lg.Warn("Rate limited, retrying", "attempt", attempt, "wait", wait, durationMS(start))
+			scrapingDogRetries.WithLabelValues("listings").Inc()
+			sleep(wait)
+			continue
+		} else if res.StatusCode != http.StatusOK {
+			lg.Error("ScrapingDog error", "attempt", attempt, "status", res.StatusCode, "body", string(bodyBytes), durationMS(start))
+			return nil, fmt.Errorf("ScrapingDog error: %s - %s", res.Status, string(bodyBytes))
+		}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
+		var pageListings []JobListing
+		if err := json.Unmarshal(bodyBytes, &pageListings); err != nil {
+			lg.Error("Failed to decode listings page", "attempt", attempt, "err", err)
+			return nil, err
+		}
+		lg.Debug("Listings page received", "attempt", attempt, durationMS(start))
+		return pageListings, nil
+	}
+	return nil, fmt.Errorf("page %d still rate limited after %d attempts", page, config.ScrapingDog.MaxRetries)
+}
+
+func getJobDescriptionWithRetry(ctx context.Context, redisDB *redis.Client, job JobListing) (desc JobDescription, err error) {
+	ctx, span := tracer.Start(ctx, "job", trace.WithAttributes(
+		attribute.String("job_id", job.JobID), attribute.String("job.position", job.JobPosition), attribute.String("job.company", job.CompanyName)))
+	defer func() { endSpan(span, err) }()
+
+	for attempt := 1; attempt <= config.ScrapingDog.MaxRetries; attempt++ {
 		desc, err = getJobDescription(ctx, redisDB, job)
 		if err == nil {
 			return desc, nil
 		}
 
-		wait := time.Duration(attempt*2) * time.Second
-		log.Printf("Retrying job %s (attempt %d/%d) after error: %v\n", job.JobID, attempt, maxRetries, err)
-		time.Sleep(wait)
+		wait := retryWait(attempt)
+		scrapingDogRetries.WithLabelValues("job").Inc()
+		logStage(stageFetch).Warn("Retrying job description", "job_id", job.JobID, "attempt", attempt, "max_attempts", config.ScrapingDog.MaxRetries, "wait", wait, "err", err)
+		sleep(wait)
 	}
 
-	return desc, fmt.Errorf("failed after %d retries: %v", maxRetries, err)
+	return desc, fmt.Errorf("job %s failed after %d retries: %v", job.JobID, config.ScrapingDog.MaxRetries, err)
 }
 
 func getJobDescription(ctx context.Context, redisDB *redis.Client, job JobListing) (JobDescription, error) {
-	log.Printf("Fetching description for JobID: %s (%s)\n", job.JobID, job.JobPosition)
 	var desc JobDescription
 
-	cacheKey := fmt.Sprintf("jobID:%s", job.JobID)
+	cacheKey := jobCacheKey(job.JobID)
 	desc, err := getFromCache(ctx, redisDB, cacheKey)
 	if err == nil {
-		log.Printf("Cache hit for JobID: %s\n", job.JobID)
+		logStage(stageCache).Debug("Cache hit", "job_id", job.JobID)
+		desc.JobID = job.JobID
+		desc.Profile = job.Profile
 		return desc, nil
 	}
-	log.Printf("Cache miss for JobID: %s\n", job.JobID)
+	logStage(stageCache).Debug("Cache miss", "job_id", job.JobID, "err", err)
+	lg := logStage(stageFetch).With("job_id", job.JobID)
 
-	apiKey := os.Getenv("SCRAPINGDOG_API_KEY")
+	apiKey := config.ScrapingDog.APIKey
 	if apiKey == "" {
-		return desc, errors.New("No API Key set in .env")
+		return desc, errors.New("no ScrapingDog API key set (scrapingdog.api_key or SCRAPINGDOG_API_KEY)")
 	}
 
 	if job.JobID == "" {
-		log.Println("JobID is empty!")
 		return desc, errors.New("Job link is empty")
 	}
 
-	apiURL := fmt.Sprintf("https://api.scrapingdog.com/linkedinjobs?api_key=%v&job_id=%v", apiKey, url.QueryEscape(job.JobID))
+	apiURL := fmt.Sprintf("%s/linkedinjobs?api_key=%v&job_id=%v", scrapingDogBaseURL(), apiKey, url.QueryEscape(job.JobID))
 
 	var resp *http.Response
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		resp, err = http.Get(apiURL)
+	start := time.Now()
+	for attempt := 1; attempt <= config.ScrapingDog.MaxRetries; attempt++ {
+		resp, err = scrapingDogGet(ctx, "job", apiURL, attribute.String("job_id", job.JobID), attribute.Int("attempt", attempt))
 		if err != nil {
-			log.Printf("HTTP request failed for JobID %s: %v\n", job.JobID, err)
+			err = redactErr(err) // the URL carries the API key
+			lg.Error("Job description request failed", "attempt", attempt, "err", err)
 			return desc, err
 		}
 
 		if resp.StatusCode == http.StatusTooManyRequests { // 429
 			bodyBytes, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			wait := time.Duration(attempt*2) * time.Second
-			log.Printf("Rate limit hit for JobID %s: %s - %s. Retrying in %v...", job.JobID, resp.Status, string(bodyBytes), wait)
-			time.Sleep(wait)
+			resp = nil
+			wait := retryWait(attempt)
+			lg.Warn("Rate limited, retrying", "attempt", attempt, "wait", wait, "body", string(bodyBytes))
+			scrapingDogRetries.WithLabelValues("job").Inc()
+			sleep(wait)
 			continue
 		} else if resp.StatusCode != http.StatusOK {
 			bodyBytes, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			log.Printf("ScrapingDog error for JobID %s: %s - %s\n", job.JobID, resp.Status, string(bodyBytes))
+			lg.Error("ScrapingDog error", "attempt", attempt, "status", resp.StatusCode, "body", string(bodyBytes))
 			return desc, fmt.Errorf("ScrapingDog error: %s - %s", resp.Status, string(bodyBytes))
 		}
 
@@ -237,36 +441,51 @@ func getJobDescription(ctx context.Context, redisDB *redis.Client, job JobListin
 	}
 	defer resp.Body.Close()
 
-	log.Printf("Decoding job description for JobID: %s\n", job.JobID)
 	decoder := json.NewDecoder(resp.Body)
 	var descs []JobDescription
 	err = decoder.Decode(&descs)
 	if err != nil {
-		log.Printf("Failed to decode job description array for JobID %s: %v\n", job.JobID, err)
+		lg.Error("Failed to decode job description", "err", err, durationMS(start))
 		return desc, err
 	}
+	if len(descs) == 0 {
+		lg.Warn("ScrapingDog returned no description", durationMS(start))
+		return desc, fmt.Errorf("no description returned for JobID %s", job.JobID)
+	}
 	desc = descs[0]
+	desc.JobID = job.JobID
+	desc.Profile = job.Profile
+	desc.FetchedAt = time.Now()
+	lg.Info("Fetched job description", "position", job.JobPosition, durationMS(start))
 	err = storeInCache(ctx, redisDB, cacheKey, desc, 24*time.Hour)
 	if err != nil {
-		log.Printf("Failed to cache JobID %s: %v\n", job.JobID, err)
+		logStage(stageCache).Warn("Failed to cache job description", "job_id", job.JobID, "err", err)
 	}
 
 	return desc, nil
 }
 
+func jobCacheKey(jobID string) string {
+	return "jobID:" + jobID
+}
+
 func getFromCache(ctx context.Context, redisDB *redis.Client, key string) (JobDescription, error) {
 	var desc JobDescription
 	cached, err := redisDB.Get(ctx, key).Result()
 	if err == redis.Nil {
+		cacheLookups.WithLabelValues("miss").Inc()
 		return desc, errors.New("cache miss")
 	} else if err != nil {
+		cacheLookups.WithLabelValues("error").Inc()
 		return desc, fmt.Errorf("cache error: %w", err)
 	}
 
 	err = json.Unmarshal([]byte(cached), &desc)
 	if err != nil {
+		cacheLookups.WithLabelValues("error").Inc()
 		return desc, fmt.Errorf("failed to decode cached data: %w", err)
 	}
+	cacheLookups.WithLabelValues("hit").Inc()
 	return desc, nil
 }
 
@@ -283,25 +502,39 @@ func storeInCache(ctx context.Context, redisDB *redis.Client, key string, desc J
 	return nil
 }
 
+// updateCachedDescription rewrites a cached description in place, keeping
+// its expiry. A description that has already expired is left gone.
+func updateCachedDescription(ctx context.Context, redisDB *redis.Client, desc JobDescription) error {
+	data, err := json.Marshal(desc)
+	if err != nil {
+		return fmt.Errorf("failed to encode data for cache: %w", err)
+	}
+	err = redisDB.SetArgs(ctx, jobCacheKey(desc.JobID), data, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	return nil
+}
+
 type jobResult struct {
 	desc JobDescription
 	err  error
 }
 
-func processJobListings(ctx context.Context, redisDB *redis.Client, jobListings []JobListing) []string {
-	log.Println("Launching throttled goroutines for job descriptions")
+func processJobListings(ctx context.Context, redisDB *redis.Client, jobListings []JobListing) []JobDescription {
+	logStage(stageFetch).Info("Fetching job descriptions", "jobs", len(jobListings), "concurrency", config.ScrapingDog.MaxConcurrentRequests)
 
 	resultChan := make(chan jobResult)
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, maxConcurrentRequests)
+	semaphore := make(chan struct{}, config.ScrapingDog.MaxConcurrentRequests)
 
 	for _, job := range jobListings {
 		wg.Add(1)
 		go func(job JobListing) {
 			defer wg.Done()
 
-			semaphore <- struct{}{}    // acquire slot
-			time.Sleep(rateLimitDelay) // wait for rate limit delay
+			semaphore <- struct{}{}                  // acquire slot
+			sleep(config.ScrapingDog.RateLimitDelay) // wait for rate limit delay
 
 			desc, err := getJobDescriptionWithRetry(ctx, redisDB, job)
 
@@ -316,23 +549,38 @@ func processJobListings(ctx context.Context, redisDB *redis.Client, jobListings
 		close(resultChan)
 	}()
 
-	return collectFormattedResults(resultChan)
+	return collectJobDescriptions(resultChan)
 }
 
-func collectFormattedResults(resultChan <-chan jobResult) []string {
-	var results []string
+func collectJobDescriptions(resultChan <-chan jobResult) []JobDescription {
+	var descs []JobDescription
 
-	log.Println("Collecting job descriptions from channel...")
 	for res := range resultChan {
 		if res.err != nil {
-			log.Printf("Error occurred during description fetch: %v\n", res.err)
+			logStage(stageFetch).Error("Skipping job after fetch failure", "err", res.err)
 			continue
 		}
+		descs = append(descs, res.desc)
+	}
+
+	return descs
+}
 
-		desc := res.desc
-		log.Printf("Formatting job: %s at %s\n", desc.JobPosition, desc.CompanyName)
-		formatted := fmt.Sprintf(
-			`Title: %s
+// collectFormattedResults drains resultChan and renders each successful
+// description as the plain-text listing shown to the model.
+func collectFormattedResults(resultChan <-chan jobResult) []string {
+	var results []string
+
+	for _, desc := range collectJobDescriptions(resultChan) {
+		results = append(results, formatJobDescription(desc))
+	}
+
+	return results
+}
+
+func formatJobDescription(desc JobDescription) string {
+	return fmt.Sprintf(
+		`Title: %s
 Company: %s
 Location: %s
 Posted: %s
@@ -343,21 +591,15 @@ Industry: %s
 Apply Link: %s
 Description: %s
 ---`,
-			desc.JobPosition,
-			desc.CompanyName,
-			desc.JobLocation,
-			desc.JobPostingTime,
-			desc.SeniorityLevel,
-			desc.EmploymentType,
-			desc.JobFunction,
-			desc.Industries,
-			desc.JobApplyLink,
-			desc.JobDescription,
-		)
-
-		results = append(results, formatted)
-	}
-
-	log.Println("Finished formatting job descriptions")
-	return results
+		desc.JobPosition,
+		desc.CompanyName,
+		desc.JobLocation,
+		desc.JobPostingTime,
+		desc.SeniorityLevel,
+		desc.EmploymentType,
+		desc.JobFunction,
+		desc.Industries,
+		desc.JobApplyLink,
+		desc.JobDescription,
+	)
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// metricsRegistry holds every pipeline metric. It is separate from the
+// Prometheus default registry so a Pushgateway push carries only our
+// series, and so tests can gather it directly.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	listingsFetched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_listings_fetched_total",
+		Help: "Job listings returned by ScrapingDog, by search profile.",
+	}, []string{"profile"})
+
+	scrapingDogRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_scrapingdog_requests_total",
+		Help: "ScrapingDog requests by endpoint (listings or job) and HTTP status code; code is \"error\" when no response came back.",
+	}, []string{"endpoint", "code"})
+
+	scrapingDogRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_scrapingdog_retries_total",
+		Help: "ScrapingDog requests retried after a rate limit or failure, by endpoint.",
+	}, []string{"endpoint"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_cache_lookups_total",
+		Help: "Job description cache lookups by result: hit, miss or error.",
+	}, []string{"result"})
+
+	llmDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scout_llm_request_duration_seconds",
+		Help:    "Ollama chat request latency by model and outcome.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"model", "outcome"})
+
+	llmTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_llm_tokens_total",
+		Help: "Tokens reported by Ollama, by model and type (prompt or completion).",
+	}, []string{"model", "type"})
+
+	llmCost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_llm_cost_usd_total",
+		Help: "Estimated LLM cost in USD from the price table, by model. Unpriced models are not counted.",
+	}, []string{"model"})
+
+	llmTokensPerSecond = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scout_llm_tokens_per_second",
+		Help:    "Completion tokens per second of generation time, as reported by Ollama, by model.",
+		Buckets: []float64{1, 2.5, 5, 10, 20, 40, 80, 160},
+	}, []string{"model"})
+
+	evaluationScores = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scout_evaluation_score",
+		Help:    "Final evaluation scores (0-100) by model.",
+		Buckets: prometheus.LinearBuckets(10, 10, 9),
+	}, []string{"model"})
+
+	emailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_emails_total",
+		Help: "Report emails by outcome: sent or failed.",
+	}, []string{"outcome"})
+
+	pipelineRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scout_runs_total",
+		Help: "Pipeline runs by outcome: success or failure.",
+	}, []string{"outcome"})
+
+	lastRunDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scout_last_run_duration_seconds",
+		Help: "How long the most recent pipeline run took.",
+	})
+
+	lastRunSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "scout_last_success_timestamp_seconds",
+		Help: "Unix time the last successful pipeline run finished.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		listingsFetched, scrapingDogRequests, scrapingDogRetries, cacheLookups,
+		llmDuration, llmTokens, llmCost, llmTokensPerSecond, evaluationScores, emailsSent,
+		pipelineRuns, lastRunDuration, lastRunSuccess,
+	)
+}
+
+// observeScrapingDog counts one ScrapingDog response, or a request that got
+// none when res is nil.
+func observeScrapingDog(endpoint string, res *http.Response) {
+	code := "error"
+	if res != nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	scrapingDogRequests.WithLabelValues(endpoint, code).Inc()
+}
+
+// observeLLM records one Ollama call. resp is nil when it failed.
+func observeLLM(model string, start time.Time, resp *Response) {
+	outcome := "error"
+	if resp != nil {
+		outcome = "ok"
+		llmTokens.WithLabelValues(model, "prompt").Add(float64(resp.PromptEvalCount))
+		llmTokens.WithLabelValues(model, "completion").Add(float64(resp.EvalCount))
+		if resp.EvalDuration > 0 {
+			llmTokensPerSecond.WithLabelValues(model).Observe(float64(resp.EvalCount) / time.Duration(resp.EvalDuration).Seconds())
+		}
+	}
+	llmDuration.WithLabelValues(model, outcome).Observe(time.Since(start).Seconds())
+}
+
+// observeRun records a finished pipeline run and pushes the metrics when a
+// Pushgateway is configured, since a one-shot run exits before anything
+// could scrape it.
+func observeRun(start time.Time, err error) {
+	lastRunDuration.Set(time.Since(start).Seconds())
+	if err != nil {
+		pipelineRuns.WithLabelValues("failure").Inc()
+	} else {
+		pipelineRuns.WithLabelValues("success").Inc()
+		lastRunSuccess.SetToCurrentTime()
+	}
+
+	if config.Metrics.Pushgateway == "" {
+		return
+	}
+	if err := pushMetrics(config.Metrics.Pushgateway, config.Metrics.Job); err != nil {
+		slog.Warn("Failed to push metrics", "pushgateway", config.Metrics.Pushgateway, "err", redactErr(err))
+		return
+	}
+	slog.Debug("Pushed metrics", "pushgateway", config.Metrics.Pushgateway, "job", config.Metrics.Job)
+}
+
+// pushMetrics replaces the job's metric group on the Pushgateway.
+func pushMetrics(url, job string) error {
+	return push.New(url, job).Gatherer(metricsRegistry).Client(httpClient).Push()
+}
+
+// metricsHandler serves the registry in the Prometheus text format.
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry})
+}
+
+// serveMetrics exposes /metrics on addr in the background for long-running
+// commands. A listener that fails to start is logged, not fatal.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler())
+	go func() {
+		slog.Info("Serving metrics", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "addr", addr, "err", err)
+		}
+	}()
+}
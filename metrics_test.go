@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// metricValue sums the counter values, or histogram sample counts, of the
+// series called name whose labels include every label given.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := metricsRegistry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	series:
+		for _, m := range f.GetMetric() {
+			have := map[string]string{}
+			for _, l := range m.GetLabel() {
+				have[l.GetName()] = l.GetValue()
+			}
+			for k, v := range labels {
+				if have[k] != v {
+					continue series
+				}
+			}
+			switch {
+			case m.GetCounter() != nil:
+				total += m.GetCounter().GetValue()
+			case m.GetHistogram() != nil:
+				total += float64(m.GetHistogram().GetSampleCount())
+			case m.GetGauge() != nil:
+				total += m.GetGauge().GetValue()
+			}
+		}
+	}
+	return total
+}
+
+func TestScrapingDogAndCacheMetrics(t *testing.T) {
+	startDevServer(t, devServerConfig{RateLimitEvery: 2})
+	ok := metricValue(t, "scout_scrapingdog_requests_total", map[string]string{"endpoint": "listings", "code": "200"})
+	limited := metricValue(t, "scout_scrapingdog_requests_total", map[string]string{"endpoint": "listings", "code": "429"})
+	retries := metricValue(t, "scout_scrapingdog_retries_total", map[string]string{"endpoint": "listings"})
+	fetched := metricValue(t, "scout_listings_fetched_total", nil)
+	cacheErrors := metricValue(t, "scout_cache_lookups_total", map[string]string{"result": "error"})
+
+	// Every second request is rate limited: 200, 429, 200.
+	if _, err := getJobListings(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if d := metricValue(t, "scout_scrapingdog_requests_total", map[string]string{"endpoint": "listings", "code": "200"}) - ok; d != 2 {
+		t.Errorf("200 responses = %v, want 2", d)
+	}
+	if d := metricValue(t, "scout_scrapingdog_requests_total", map[string]string{"endpoint": "listings", "code": "429"}) - limited; d != 1 {
+		t.Errorf("429 responses = %v, want 1", d)
+	}
+	if d := metricValue(t, "scout_scrapingdog_retries_total", map[string]string{"endpoint": "listings"}) - retries; d != 1 {
+		t.Errorf("retries = %v, want 1", d)
+	}
+	if d := metricValue(t, "scout_listings_fetched_total", map[string]string{"profile": searchProfile()}) - fetched; d != 12 {
+		t.Errorf("listings fetched = %v, want 12", d)
+	}
+
+	if _, err := getFromCache(context.Background(), offlineRedis(t), "jobID:1"); err == nil {
+		t.Fatal("expected a cache error with Redis down")
+	}
+	if d := metricValue(t, "scout_cache_lookups_total", map[string]string{"result": "error"}) - cacheErrors; d != 1 {
+		t.Errorf("cache errors = %v, want 1", d)
+	}
+}
+
+func TestLLMMetrics(t *testing.T) {
+	startDevServer(t, devServerConfig{})
+	calls := metricValue(t, "scout_llm_request_duration_seconds", map[string]string{"model": "metrics-model", "outcome": "ok"})
+	tokens := metricValue(t, "scout_llm_tokens_total", map[string]string{"model": "metrics-model"})
+
+	rubric := defaultRubric
+	if _, err := evaluateSample(context.Background(), evalSettings{rubric: &rubric}, "metrics-model", "system", "Software Engineer Intern at Acme"); err != nil {
+		t.Fatal(err)
+	}
+	if d := metricValue(t, "scout_llm_request_duration_seconds", map[string]string{"model": "metrics-model", "outcome": "ok"}) - calls; d != 1 {
+		t.Errorf("LLM calls = %v, want 1", d)
+	}
+	if d := metricValue(t, "scout_llm_tokens_total", map[string]string{"model": "metrics-model"}) - tokens; d <= 0 {
+		t.Errorf("no tokens counted")
+	}
+}
+
+func TestMetricsEndpointAndPush(t *testing.T) {
+	emailsSent.WithLabelValues("sent").Add(0) // make sure the family has a series
+	srv := httptest.NewServer(newServeMux(testStores(t)[dbSQLite]))
+	defer srv.Close()
+	res, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "scout_emails_total") {
+		t.Errorf("GET /metrics: %s\n%s", res.Status, body)
+	}
+
+	var method, path, pushed string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, pushed = r.Method, r.URL.Path, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+	if err := pushMetrics(gateway.URL, "scout_test"); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/scout_test" || len(pushed) == 0 {
+		t.Errorf("push: %s %s (%d bytes)", method, path, len(pushed))
+	}
+}
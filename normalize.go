@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Workplace string
+
+const (
+	WorkplaceUnknown Workplace = "unknown"
+	WorkplaceRemote  Workplace = "remote"
+	WorkplaceHybrid  Workplace = "hybrid"
+	WorkplaceOnsite  Workplace = "onsite"
+)
+
+type Seniority string
+
+const (
+	SeniorityUnknown    Seniority = "unknown"
+	SeniorityInternship Seniority = "internship"
+	SeniorityEntry      Seniority = "entry"
+	SeniorityAssociate  Seniority = "associate"
+	SeniorityMidSenior  Seniority = "mid_senior"
+	SeniorityDirector   Seniority = "director"
+	SeniorityExecutive  Seniority = "executive"
+)
+
+type EmploymentType string
+
+const (
+	EmploymentUnknown    EmploymentType = "unknown"
+	EmploymentFullTime   EmploymentType = "full_time"
+	EmploymentPartTime   EmploymentType = "part_time"
+	EmploymentContract   EmploymentType = "contract"
+	EmploymentTemporary  EmploymentType = "temporary"
+	EmploymentInternship EmploymentType = "internship"
+	EmploymentVolunteer  EmploymentType = "volunteer"
+	EmploymentOther      EmploymentType = "other"
+)
+
+type Location struct {
+	City      string    `json:"city,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	Workplace Workplace `json:"workplace"`
+	Raw       string    `json:"raw"`
+}
+
+func (l Location) String() string {
+	var parts []string
+	for _, p := range []string{l.City, l.Region, l.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, l.Raw)
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, ", "), l.Workplace)
+}
+
+// Job is the normalized form of a JobDescription used by filters, sorting
+// and reports. The raw strings stay available on JobDescription.
+type Job struct {
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	Company        string         `json:"company"`
+	PostedAt       time.Time      `json:"posted_at"` // zero when the posting time couldn't be parsed
+	Location       Location       `json:"location"`
+	Seniority      Seniority      `json:"seniority"`
+	EmploymentType EmploymentType `json:"employment_type"`
+	Industries     []string       `json:"industries,omitempty"`
+	Functions      []string       `json:"functions,omitempty"`
+	ApplyLink      string         `json:"apply_link"`
+	Compensation   *Compensation  `json:"compensation,omitempty"`
+	Requirements   Requirements   `json:"requirements"`
+}
+
+// normalizeJob parses the raw description fields. Relative posting times
+// ("2 days ago") are resolved against when the description was fetched.
+func normalizeJob(desc JobDescription) Job {
+	ref := desc.FetchedAt
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+	postedAt, _ := parsePostingTime(desc.JobPostingTime, ref)
+
+	return Job{
+		ID:             desc.JobID,
+		Title:          strings.TrimSpace(desc.JobPosition),
+		Company:        strings.TrimSpace(desc.CompanyName),
+		PostedAt:       postedAt,
+		Location:       parseLocation(desc.JobLocation, desc.JobDescription),
+		Seniority:      parseSeniority(desc.SeniorityLevel),
+		EmploymentType: parseEmploymentType(desc.EmploymentType),
+		Industries:     splitList(desc.Industries, false),
+		Functions:      splitList(desc.JobFunction, true),
+		ApplyLink:      desc.JobApplyLink,
+		Compensation:   jobCompensation(desc),
+		Requirements:   extractRequirements(desc.JobDescription),
+	}
+}
+
+var relativeTimePattern = regexp.MustCompile(`(?i)(\d+|an?|one)\s+(second|minute|hour|day|week|month|year)s?\s+ago`)
+
+var absoluteTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// parsePostingTime understands LinkedIn's "3 days ago" style as well as
+// absolute dates.
+func parsePostingTime(raw string, ref time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.Contains(lower, "just now"), strings.Contains(lower, "today"):
+		return ref, true
+	case strings.Contains(lower, "yesterday"):
+		return ref.AddDate(0, 0, -1), true
+	}
+
+	if m := relativeTimePattern.FindStringSubmatch(raw); m != nil {
+		n := 1
+		if v, err := strconv.Atoi(m[1]); err == nil {
+			n = v
+		}
+		switch strings.ToLower(m[2]) {
+		case "second":
+			return ref.Add(-time.Duration(n) * time.Second), true
+		case "minute":
+			return ref.Add(-time.Duration(n) * time.Minute), true
+		case "hour":
+			return ref.Add(-time.Duration(n) * time.Hour), true
+		case "day":
+			return ref.AddDate(0, 0, -n), true
+		case "week":
+			return ref.AddDate(0, 0, -7*n), true
+		case "month":
+			return ref.AddDate(0, -n, 0), true
+		case "year":
+			return ref.AddDate(-n, 0, 0), true
+		}
+	}
+
+	for _, layout := range absoluteTimeLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var usStates = map[string]bool{
+	"AL": true, "AK": true, "AZ": true, "AR": true, "CA": true, "CO": true, "CT": true, "DE": true, "DC": true,
+	"FL": true, "GA": true, "HI": true, "ID": true, "IL": true, "IN": true, "IA": true, "KS": true, "KY": true,
+	"LA": true, "ME": true, "MD": true, "MA": true, "MI": true, "MN": true, "MS": true, "MO": true, "MT": true,
+	"NE": true, "NV": true, "NH": true, "NJ": true, "NM": true, "NY": true, "NC": true, "ND": true, "OH": true,
+	"OK": true, "OR": true, "PA": true, "RI": true, "SC": true, "SD": true, "TN": true, "TX": true, "UT": true,
+	"VT": true, "VA": true, "WA": true, "WV": true, "WI": true, "WY": true,
+}
+
+var remoteDescriptionPattern = regexp.MustCompile(`(?i)\b(fully remote|100% remote|remote[- ]first|remote position|remote role|work from home|work from anywhere)\b`)
+var hybridDescriptionPattern = regexp.MustCompile(`(?i)\bhybrid\b`)
+
+// workplaceMarkerPattern matches the workplace part of a location, like
+// "(Remote)" or "Hybrid", in any case.
+var workplaceMarkerPattern = regexp.MustCompile(`(?i)\(?\b(remote|hybrid|on-?site)\b\)?`)
+
+// parseLocation splits LinkedIn's "City, ST", "City, Region, Country" and
+// "Country" forms, and works out remote/hybrid/onsite from the location or,
+// failing that, the description text.
+func parseLocation(raw, description string) Location {
+	loc := Location{Raw: raw, Workplace: WorkplaceUnknown}
+
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.Contains(lower, "remote"):
+		loc.Workplace = WorkplaceRemote
+	case strings.Contains(lower, "hybrid"):
+		loc.Workplace = WorkplaceHybrid
+	case strings.Contains(lower, "on-site"), strings.Contains(lower, "onsite"):
+		loc.Workplace = WorkplaceOnsite
+	}
+	clean := workplaceMarkerPattern.ReplaceAllString(raw, "")
+
+	var parts []string
+	for _, p := range strings.Split(clean, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		if strings.HasSuffix(parts[0], "Metropolitan Area") || strings.HasSuffix(parts[0], "Bay Area") {
+			loc.Region = parts[0]
+		} else {
+			loc.Country = parts[0]
+		}
+	case 2:
+		switch {
+		case usStates[strings.ToUpper(parts[1])]:
+			loc.City, loc.Region, loc.Country = parts[0], strings.ToUpper(parts[1]), "United States"
+		case strings.EqualFold(parts[1], "United States"):
+			loc.Region, loc.Country = parts[0], parts[1]
+		default:
+			loc.City, loc.Country = parts[0], parts[1]
+		}
+	case 3:
+		loc.City, loc.Region, loc.Country = parts[0], parts[1], parts[2]
+	}
+
+	if loc.Workplace == WorkplaceUnknown {
+		switch {
+		case hybridDescriptionPattern.MatchString(description):
+			loc.Workplace = WorkplaceHybrid
+		case remoteDescriptionPattern.MatchString(description):
+			loc.Workplace = WorkplaceRemote
+		case loc.City != "":
+			loc.Workplace = WorkplaceOnsite
+		}
+	}
+	return loc
+}
+
+func parseSeniority(raw string) Seniority {
+	switch s := strings.ToLower(strings.TrimSpace(raw)); {
+	case s == "":
+		return SeniorityUnknown
+	case strings.Contains(s, "intern"):
+		return SeniorityInternship
+	case strings.Contains(s, "entry"):
+		return SeniorityEntry
+	case strings.Contains(s, "associate"):
+		return SeniorityAssociate
+	case strings.Contains(s, "mid"), strings.Contains(s, "senior"):
+		return SeniorityMidSenior
+	case strings.Contains(s, "director"):
+		return SeniorityDirector
+	case strings.Contains(s, "executive"):
+		return SeniorityExecutive
+	default:
+		return SeniorityUnknown
+	}
+}
+
+func parseEmploymentType(raw string) EmploymentType {
+	switch s := strings.ToLower(strings.TrimSpace(raw)); {
+	case s == "":
+		return EmploymentUnknown
+	case strings.Contains(s, "full"):
+		return EmploymentFullTime
+	case strings.Contains(s, "part"):
+		return EmploymentPartTime
+	case strings.Contains(s, "contract"):
+		return EmploymentContract
+	case strings.Contains(s, "temp"):
+		return EmploymentTemporary
+	case strings.Contains(s, "intern"):
+		return EmploymentInternship
+	case strings.Contains(s, "volunteer"):
+		return EmploymentVolunteer
+	default:
+		return EmploymentOther
+	}
+}
+
+// splitList splits LinkedIn's comma-separated lists. Job functions also join
+// two values with " and " ("Engineering and Information Technology"), while
+// industry names can contain "and" themselves, so only functions split on it.
+func splitList(raw string, splitAnd bool) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		pieces := []string{part}
+		if splitAnd {
+			pieces = strings.Split(part, " and ")
+		}
+		for _, p := range pieces {
+			if p = strings.TrimSpace(p); p != "" && !slices.Contains(out, p) {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// jobFilters narrows which jobs get evaluated. Empty fields don't filter.
+type jobFilters struct {
+	Workplaces      []Workplace
+	Seniorities     []Seniority
+	EmploymentTypes []EmploymentType
+	Countries       []string
+	MaxAge          time.Duration
+	MinSalary       float64 // annual USD; jobs without stated pay still pass
+}
+
+var (
+	allWorkplaces      = []Workplace{WorkplaceUnknown, WorkplaceRemote, WorkplaceHybrid, WorkplaceOnsite}
+	allSeniorities     = []Seniority{SeniorityUnknown, SeniorityInternship, SeniorityEntry, SeniorityAssociate, SeniorityMidSenior, SeniorityDirector, SeniorityExecutive}
+	allEmploymentTypes = []EmploymentType{EmploymentUnknown, EmploymentFullTime, EmploymentPartTime, EmploymentContract, EmploymentTemporary, EmploymentInternship, EmploymentVolunteer, EmploymentOther}
+)
+
+func loadJobFilters(c *Config) (jobFilters, error) {
+	f := jobFilters{
+		Countries: splitList(c.Filter.Country, false),
+		MaxAge:    c.Filter.MaxAge,
+		MinSalary: c.Filter.MinSalary,
+	}
+	var err error
+	if f.Workplaces, err = parseEnumList("filter.workplace", c.Filter.Workplace, allWorkplaces); err != nil {
+		return f, err
+	}
+	if f.Seniorities, err = parseEnumList("filter.seniority", c.Filter.Seniority, allSeniorities); err != nil {
+		return f, err
+	}
+	if f.EmploymentTypes, err = parseEnumList("filter.employment_type", c.Filter.EmploymentType, allEmploymentTypes); err != nil {
+		return f, err
+	}
+	if f.MaxAge < 0 {
+		return f, fmt.Errorf("filter.max_age must not be negative, got %s", f.MaxAge)
+	}
+	if f.MinSalary < 0 {
+		return f, fmt.Errorf("filter.min_salary must be a non-negative annual USD amount, got %g", f.MinSalary)
+	}
+	return f, nil
+}
+
+func parseEnumList[T ~string](key, list string, allowed []T) ([]T, error) {
+	var out []T
+	for _, v := range splitList(list, false) {
+		if !slices.Contains(allowed, T(v)) {
+			return nil, fmt.Errorf("%s: unknown value %q (allowed: %v)", key, v, allowed)
+		}
+		out = append(out, T(v))
+	}
+	return out, nil
+}
+
+// matches reports whether job passes every configured filter. Jobs whose
+// posting time is unknown are kept by the age filter.
+func (f jobFilters) matches(job Job, now time.Time) bool {
+	if len(f.Workplaces) > 0 && !slices.Contains(f.Workplaces, job.Location.Workplace) {
+		return false
+	}
+	if len(f.Seniorities) > 0 && !slices.Contains(f.Seniorities, job.Seniority) {
+		return false
+	}
+	if len(f.EmploymentTypes) > 0 && !slices.Contains(f.EmploymentTypes, job.EmploymentType) {
+		return false
+	}
+	if len(f.Countries) > 0 && !slices.ContainsFunc(f.Countries, func(c string) bool {
+		return strings.EqualFold(c, job.Location.Country)
+	}) {
+		return false
+	}
+	if f.MaxAge > 0 && !job.PostedAt.IsZero() && now.Sub(job.PostedAt) > f.MaxAge {
+		return false
+	}
+	if f.MinSalary > 0 && job.Compensation != nil && job.Compensation.AnnualMaxUSD < f.MinSalary {
+		return false
+	}
+	return true
+}
+
+func filterJobDescriptions(descs []JobDescription, f jobFilters) []JobDescription {
+	now := time.Now()
+	var kept []JobDescription
+	for _, desc := range descs {
+		if f.matches(normalizeJob(desc), now) {
+			kept = append(kept, desc)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParsePostingTime(t *testing.T) {
+	ref := time.Date(2025, 8, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2 days ago":   ref.AddDate(0, 0, -2),
+		"1 week ago":   ref.AddDate(0, 0, -7),
+		"an hour ago":  ref.Add(-time.Hour),
+		"3 months ago": ref.AddDate(0, -3, 0),
+		"Just now":     ref,
+		"2025-08-01":   time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for raw, want := range cases {
+		got, ok := parsePostingTime(raw, ref)
+		if !ok || !got.Equal(want) {
+			t.Errorf("parsePostingTime(%q) = %v, %v; want %v", raw, got, ok, want)
+		}
+	}
+	if _, ok := parsePostingTime("sometime", ref); ok {
+		t.Error("expected unparseable posting time to fail")
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	cases := []struct {
+		raw, description string
+		want             Location
+	}{
+		{"New York, NY", "", Location{City: "New York", Region: "NY", Country: "United States", Workplace: WorkplaceOnsite}},
+		{"Austin, Texas, United States", "This is a hybrid role.", Location{City: "Austin", Region: "Texas", Country: "United States", Workplace: WorkplaceHybrid}},
+		{"United States (Remote)", "", Location{Country: "United States", Workplace: WorkplaceRemote}},
+		{"United States (remote)", "", Location{Country: "United States", Workplace: WorkplaceRemote}},
+		{"Denver, CO REMOTE", "", Location{City: "Denver", Region: "CO", Country: "United States", Workplace: WorkplaceRemote}},
+		{"Berlin, Germany (onsite)", "", Location{City: "Berlin", Country: "Germany", Workplace: WorkplaceOnsite}},
+		{"California, United States", "We are a remote-first company.", Location{Region: "California", Country: "United States", Workplace: WorkplaceRemote}},
+		{"San Francisco Bay Area", "", Location{Region: "San Francisco Bay Area", Workplace: WorkplaceUnknown}},
+		{"London, United Kingdom", "", Location{City: "London", Country: "United Kingdom", Workplace: WorkplaceOnsite}},
+	}
+	for _, c := range cases {
+		got := parseLocation(c.raw, c.description)
+		c.want.Raw = c.raw
+		if got != c.want {
+			t.Errorf("parseLocation(%q) = %+v; want %+v", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeJobEnumsAndLists(t *testing.T) {
+	job := normalizeJob(JobDescription{
+		JobID:          "1",
+		SeniorityLevel: "Mid-Senior level",
+		EmploymentType: "Full-time",
+		JobFunction:    "Engineering and Information Technology",
+		Industries:     "IT Services and IT Consulting, Software Development",
+		JobPostingTime: "1 day ago",
+		FetchedAt:      time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC),
+	})
+
+	if job.Seniority != SeniorityMidSenior || job.EmploymentType != EmploymentFullTime {
+		t.Errorf("unexpected enums: %s, %s", job.Seniority, job.EmploymentType)
+	}
+	if !slices.Equal(job.Functions, []string{"Engineering", "Information Technology"}) {
+		t.Errorf("unexpected functions %v", job.Functions)
+	}
+	if !slices.Equal(job.Industries, []string{"IT Services and IT Consulting", "Software Development"}) {
+		t.Errorf("unexpected industries %v", job.Industries)
+	}
+	if !job.PostedAt.Equal(time.Date(2025, 8, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected posted at %v", job.PostedAt)
+	}
+}
+
+func TestJobFilters(t *testing.T) {
+	now := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	remote := Job{Location: Location{Country: "United States", Workplace: WorkplaceRemote}, Seniority: SeniorityInternship, PostedAt: now.AddDate(0, 0, -1)}
+	onsite := Job{Location: Location{Country: "Canada", Workplace: WorkplaceOnsite}, Seniority: SeniorityInternship, PostedAt: now.AddDate(0, 0, -10)}
+
+	f := jobFilters{Workplaces: []Workplace{WorkplaceRemote, WorkplaceHybrid}}
+	if !f.matches(remote, now) || f.matches(onsite, now) {
+		t.Error("workplace filter mismatch")
+	}
+
+	f = jobFilters{Countries: []string{"united states"}, MaxAge: 72 * time.Hour}
+	if !f.matches(remote, now) || f.matches(onsite, now) {
+		t.Error("country/age filter mismatch")
+	}
+
+	if !(jobFilters{MaxAge: time.Hour}).matches(Job{}, now) {
+		t.Error("expected jobs with unknown posting time to pass the age filter")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// pipelineOptions changes where the pipeline gets its jobs and what it does
+// with the report.
+type pipelineOptions struct {
+	Offline bool          // use only cached and stored jobs; no ScrapingDog calls and no email
+	Since   time.Duration // offline: only jobs seen this recently (0 = all)
+}
+
+// offlineJobDescriptions collects jobs without calling ScrapingDog: every
+// job in the history seen within since, plus descriptions still in the
+// Redis cache that never made it into the history. The latter are stored
+// so the run's evaluations have somewhere to go. An unreachable Redis just
+// leaves the history.
+func offlineJobDescriptions(ctx context.Context, redisDB *redis.Client, repo repository, ix *jobIndex, since time.Duration) ([]JobDescription, error) {
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	ix.mu.Lock()
+	var descs []JobDescription
+	for _, job := range ix.Jobs {
+		if job.LastSeen.Before(cutoff) {
+			continue
+		}
+		descs = append(descs, job.Description)
+	}
+	ix.mu.Unlock()
+	slog.Info("Offline: jobs from history", "jobs", len(descs))
+
+	cached, err := cachedJobDescriptions(ctx, redisDB)
+	if err != nil {
+		logStage(stageCache).Warn("Offline: skipping the Redis cache", "err", err)
+	}
+	var fresh []JobDescription
+	for _, desc := range cached {
+		ix.mu.Lock()
+		_, known := ix.Jobs[desc.JobID]
+		ix.mu.Unlock()
+		if !known && !desc.FetchedAt.Before(cutoff) {
+			fresh = append(fresh, desc)
+		}
+	}
+	if len(fresh) > 0 {
+		logStage(stageCache).Info("Offline: more jobs from the Redis cache", "jobs", len(fresh))
+		if err := indexJobDescriptions(ctx, redisDB, repo, ix, fresh); err != nil {
+			return nil, err
+		}
+		descs = append(descs, fresh...)
+	}
+
+	sort.Slice(descs, func(i, j int) bool { return descs[i].JobID < descs[j].JobID })
+	return descs, nil
+}
+
+// splitEvaluated separates jobs that still need an evaluation from those
+// the history already has one for, returning the stored evaluations so
+// they can go in the report as they are.
+func splitEvaluated(ix *jobIndex, descs []JobDescription) ([]JobDescription, []Evaluation) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var pending []JobDescription
+	var previous []Evaluation
+	for _, desc := range descs {
+		if job, ok := ix.Jobs[desc.JobID]; ok {
+			if eval := job.latestEvaluation(); eval != nil {
+				previous = append(previous, *eval)
+				continue
+			}
+		}
+		pending = append(pending, desc)
+	}
+	return pending, previous
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOfflineJobDescriptions(t *testing.T) {
+	ctx := context.Background()
+	s := testStores(t)[dbSQLite]
+	now := time.Now()
+	seedStore(t, s,
+		&indexedJob{Description: JobDescription{JobID: "recent"}, FirstSeen: now.Add(-time.Hour), LastSeen: now.Add(-time.Hour)},
+		&indexedJob{Description: JobDescription{JobID: "evaluated"}, FirstSeen: now.Add(-2 * time.Hour), LastSeen: now.Add(-2 * time.Hour),
+			Evaluations: []Evaluation{{JobID: "evaluated", Score: 64}}},
+		&indexedJob{Description: JobDescription{JobID: "old"}, FirstSeen: now.Add(-30 * 24 * time.Hour), LastSeen: now.Add(-30 * 24 * time.Hour)})
+	ix, err := loadJobIndex(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Redis is down, so only the history is used.
+	descs, err := offlineJobDescriptions(ctx, offlineRedis(t), s, ix, 7*24*time.Hour)
+	if err != nil || len(descs) != 2 || descs[0].JobID != "evaluated" || descs[1].JobID != "recent" {
+		t.Fatalf("got %+v, %v; want evaluated and recent", descs, err)
+	}
+	if all, err := offlineJobDescriptions(ctx, offlineRedis(t), s, ix, 0); err != nil || len(all) != 3 {
+		t.Errorf("with no window got %d jobs, %v; want 3", len(all), err)
+	}
+
+	pending, previous := splitEvaluated(ix, descs)
+	if len(pending) != 1 || pending[0].JobID != "recent" {
+		t.Errorf("pending = %+v, want only recent", pending)
+	}
+	if len(previous) != 1 || previous[0].Score != 64 {
+		t.Errorf("previous = %+v, want the stored evaluation", previous)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var embeddedPrompts embed.FS
+
+// Templates the pipeline expects to find, either in eval.prompt_dir or embedded.
+const (
+	systemPromptName   = "system"
+	evaluatePromptName = "evaluate"
+)
+
+var promptVersionPattern = regexp.MustCompile(`^\s*\{\{/\*\s*version:\s*([^\s*]+)\s*\*/\}\}`)
+
+type PromptTemplate struct {
+	Name    string
+	Version string // declared version plus a content hash, e.g. "v2-1a2b3c4d"
+	Source  string // file path, or "embedded"
+	Text    string
+	tmpl    *template.Template
+}
+
+// PromptData holds the variables available to every prompt template.
+type PromptData struct {
+	Resume        string
+	CandidateName string
+	Rubric        *Rubric
+	Examples      []PromptExample // past evaluations with our verdicts, newest first
+	Job           PromptJob
+}
+
+type PromptJob struct {
+	ID             string
+	Title          string
+	Company        string
+	Location       string
+	Posted         string
+	SeniorityLevel string
+	EmploymentType string
+	JobFunction    string
+	Industries     string
+	ApplyLink      string
+	Description    string
+	Formatted      string // the full listing as shown to the model before templates existed
+}
+
+func newPromptJob(desc JobDescription) PromptJob {
+	return PromptJob{
+		ID:             desc.JobID,
+		Title:          desc.JobPosition,
+		Company:        desc.CompanyName,
+		Location:       desc.JobLocation,
+		Posted:         desc.JobPostingTime,
+		SeniorityLevel: desc.SeniorityLevel,
+		EmploymentType: desc.EmploymentType,
+		JobFunction:    desc.JobFunction,
+		Industries:     desc.Industries,
+		ApplyLink:      desc.JobApplyLink,
+		Description:    desc.JobDescription,
+		Formatted:      formatJobDescription(desc),
+	}
+}
+
+// loadPromptTemplate prefers <eval.prompt_dir>/<name>.tmpl on disk so prompts can be
+// edited without recompiling, and falls back to the copy embedded in the binary.
+func loadPromptTemplate(name string) (*PromptTemplate, error) {
+	path := filepath.Join(config.Eval.PromptDir, name+".tmpl")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return parsePromptTemplate(name, path, string(data))
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read prompt %s: %w", path, err)
+	}
+
+	data, err = embeddedPrompts.ReadFile("prompts/" + name + ".tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("prompt template %q not found in %s or embedded defaults", name, config.Eval.PromptDir)
+	}
+	return parsePromptTemplate(name, "embedded", string(data))
+}
+
+func parsePromptTemplate(name, source, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt %s (%s): %w", name, source, err)
+	}
+
+	declared := "unversioned"
+	if m := promptVersionPattern.FindStringSubmatch(text); m != nil {
+		declared = m[1]
+	}
+	sum := sha256.Sum256([]byte(text))
+
+	return &PromptTemplate{
+		Name:    name,
+		Version: declared + "-" + hex.EncodeToString(sum[:4]),
+		Source:  source,
+		Text:    text,
+		tmpl:    tmpl,
+	}, nil
+}
+
+func (p *PromptTemplate) Render(data PromptData) (string, error) {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s@%s: %w", p.Name, p.Version, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// ID identifies the exact prompt wording that produced an evaluation.
+func (p *PromptTemplate) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// listPromptTemplates returns every template name found on disk or embedded,
+// with on-disk files shadowing embedded ones of the same name.
+func listPromptTemplates() ([]*PromptTemplate, error) {
+	names := map[string]bool{}
+
+	entries, err := embeddedPrompts.ReadDir("prompts")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		names[strings.TrimSuffix(e.Name(), ".tmpl")] = true
+	}
+
+	files, _ := filepath.Glob(filepath.Join(config.Eval.PromptDir, "*.tmpl"))
+	for _, f := range files {
+		names[strings.TrimSuffix(filepath.Base(f), ".tmpl")] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var templates []*PromptTemplate
+	for _, name := range sorted {
+		t, err := loadPromptTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// samplePromptData exercises every variable so validation catches typos in
+// field names, which text/template only reports at execution time.
+func samplePromptData() PromptData {
+	return PromptData{
+		Resume:        "Sample resume",
+		CandidateName: "Sample Candidate",
+		Rubric:        &defaultRubric,
+		Examples: []PromptExample{
+			{Title: "Backend Intern", Company: "Sample Co", Score: 72, Verdict: feedbackLabels[FeedbackTooHigh], Note: "needs 3 years of Java"},
+		},
+		Job: newPromptJob(JobDescription{
+			JobID:          "0",
+			JobPosition:    "Software Engineer Intern",
+			CompanyName:    "Example Corp",
+			JobLocation:    "Remote",
+			JobPostingTime: "1 day ago",
+			JobDescription: "Build things.",
+			JobApplyLink:   "https://example.com/apply",
+		}),
+	}
+}
+
+func validatePromptTemplate(t *PromptTemplate) error {
+	_, err := t.Render(samplePromptData())
+	return err
+}
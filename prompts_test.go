@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedPromptsValidate(t *testing.T) {
+	for _, name := range []string{systemPromptName, evaluatePromptName} {
+		data, err := embeddedPrompts.ReadFile("prompts/" + name + ".tmpl")
+		if err != nil {
+			t.Fatalf("embedded prompt %s missing: %v", name, err)
+		}
+		tmpl, err := parsePromptTemplate(name, "embedded", string(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validatePromptTemplate(tmpl); err != nil {
+			t.Errorf("embedded prompt %s failed validation: %v", name, err)
+		}
+	}
+}
+
+func TestPromptVersion(t *testing.T) {
+	a, err := parsePromptTemplate("x", "test", "{{/* version: v3 */}}Hello {{.CandidateName}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a.Version, "v3-") {
+		t.Errorf("expected declared version prefix, got %q", a.Version)
+	}
+
+	b, _ := parsePromptTemplate("x", "test", "{{/* version: v3 */}}Hi {{.CandidateName}}")
+	if a.Version == b.Version {
+		t.Errorf("expected wording change to change version, both %q", a.Version)
+	}
+
+	c, _ := parsePromptTemplate("x", "test", "Hi")
+	if !strings.HasPrefix(c.Version, "unversioned-") {
+		t.Errorf("expected unversioned prefix, got %q", c.Version)
+	}
+
+	out, err := a.Render(PromptData{CandidateName: "Sam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Hello Sam" {
+		t.Errorf("unexpected render output %q", out)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownSecrets holds every resolved secret value, so it can be scrubbed
+// wherever it turns up, not only where we expect it.
+var knownSecrets struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// minSecretLen keeps short values such as a Redis password of "1" from
+// turning every digit in the logs into asterisks.
+const minSecretLen = 4
+
+// secretPatterns catch secrets we were never told about: query parameters,
+// JSON fields and headers with telling names, and passwords in URLs.
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)\b((?:api_?key|access_token|token|secret|password|passwd)=)[^&\s"']+`), "${1}" + redactedValue},
+	{regexp.MustCompile(`(?i)("(?:api_?key|access_token|token|secret|password|passwd)"\s*:\s*")[^"]*`), "${1}" + redactedValue},
+	{regexp.MustCompile(`(?i)\b(authorization:\s*(?:bearer|basic|token)\s+)\S+`), "${1}" + redactedValue},
+	{regexp.MustCompile(`(\b[a-z][a-z0-9+.-]*://[^:/@\s]+:)[^@/\s]+@`), "${1}" + redactedValue + "@"},
+}
+
+// addSecret registers a value to scrub from logs and errors.
+func addSecret(v string) {
+	if len(v) < minSecretLen {
+		return
+	}
+	knownSecrets.mu.Lock()
+	defer knownSecrets.mu.Unlock()
+	for _, s := range knownSecrets.values {
+		if s == v {
+			return
+		}
+	}
+	knownSecrets.values = append(knownSecrets.values, v)
+	// Longest first, so a secret containing another is replaced whole.
+	sort.Slice(knownSecrets.values, func(i, j int) bool {
+		return len(knownSecrets.values[i]) > len(knownSecrets.values[j])
+	})
+}
+
+// redactSecrets scrubs known secrets and anything that looks like one.
+func redactSecrets(s string) string {
+	knownSecrets.mu.RLock()
+	for _, v := range knownSecrets.values {
+		s = strings.ReplaceAll(s, v, redactedValue)
+	}
+	knownSecrets.mu.RUnlock()
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// redactingWriter scrubs everything written through it. The log package
+// writes one whole entry per call, so secrets are never split across writes.
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, redactSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactedError hides secrets in an error's message, e.g. the API key in
+// the URL of a *url.Error, while keeping it available to errors.Is and As.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string { return redactSecrets(e.err.Error()) }
+func (e redactedError) Unwrap() error { return e.err }
+
+// redactErr wraps err so its message is safe to print anywhere.
+func redactErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return redactedError{err: err}
+}
+
+// dumpTransport logs every request and response in full when http.debug
+// is set. The dumps go through redactSecrets before they reach the log.
+type dumpTransport struct {
+	next http.RoundTripper
+}
+
+func (t dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		slog.Info("HTTP request", "dump", redactSecrets(string(dump)))
+	}
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Info("HTTP error", "url", redactSecrets(req.URL.String()), "err", redactErr(err))
+		return nil, err
+	}
+	if dump, err := httputil.DumpResponse(res, true); err == nil {
+		slog.Info("HTTP response", "dump", redactSecrets(string(dump)))
+	}
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultRubricFile = "rubric.json"
+
+type Criterion struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Description string  `json:"description"`
+}
+
+type Rubric struct {
+	Criteria []Criterion `json:"criteria"`
+}
+
+// SubScore is the model's score for one rubric criterion.
+type SubScore struct {
+	Key           string
+	Name          string
+	Weight        float64
+	Score         int
+	Justification string
+	Missing       bool // the model did not return this criterion; scored as 0
+}
+
+var criterionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var defaultRubric = Rubric{Criteria: []Criterion{
+	{Key: "skills", Name: "Skills Match", Weight: 40, Description: "Overlap between the technologies and skills in the resume and those the job asks for"},
+	{Key: "experience", Name: "Experience Level", Weight: 20, Description: "Whether years and kind of experience fit the seniority of the role"},
+	{Key: "location", Name: "Location/Remote", Weight: 15, Description: "Whether the location or remote policy works for the candidate"},
+	{Key: "domain", Name: "Domain", Weight: 15, Description: "Familiarity with the company's industry and problem domain"},
+	{Key: "education", Name: "Education", Weight: 10, Description: "Degree and certification requirements compared to the resume"},
+}}
+
+// loadRubric reads eval.rubric_file (or rubric.json if present) and falls
+// back to the built-in default rubric.
+func loadRubric(c *Config) (*Rubric, error) {
+	path := c.Eval.RubricFile
+	if path == "" {
+		path = defaultRubricFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && c.Eval.RubricFile == "" {
+		r := defaultRubric
+		return &r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rubric %s: %w", path, err)
+	}
+
+	var r Rubric
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to decode rubric %s: %w", path, err)
+	}
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rubric %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+func (r *Rubric) validate() error {
+	if len(r.Criteria) == 0 {
+		return errors.New("rubric has no criteria")
+	}
+	seen := map[string]bool{}
+	for _, c := range r.Criteria {
+		if c.Key == "" || c.Name == "" {
+			return fmt.Errorf("criterion %+v needs both key and name", c)
+		}
+		if !criterionKeyPattern.MatchString(c.Key) {
+			return fmt.Errorf("criterion key %q must be lowercase letters, digits or underscores", c.Key)
+		}
+		if use, ok := reservedSortKeys[c.Key]; ok {
+			return fmt.Errorf("criterion key %q is reserved for %s", c.Key, use)
+		}
+		if seen[c.Key] {
+			return fmt.Errorf("duplicate criterion key %q", c.Key)
+		}
+		if c.Weight <= 0 {
+			return fmt.Errorf("criterion %q must have a positive weight", c.Key)
+		}
+		seen[c.Key] = true
+	}
+	return nil
+}
+
+// reservedSortKeys are the report's other sort keys. Criterion keys share
+// their namespace in eval.sort_by and the report's data attributes.
+var reservedSortKeys = map[string]string{
+	"score":  "the weighted total",
+	"posted": "sorting by posting date",
+	"salary": "sorting by salary",
+}
+
+func (r *Rubric) totalWeight() float64 {
+	var total float64
+	for _, c := range r.Criteria {
+		total += c.Weight
+	}
+	return total
+}
+
+// String renders the rubric for the prompt, with weights as percentages.
+func (r *Rubric) String() string {
+	var sb strings.Builder
+	total := r.totalWeight()
+	for _, c := range r.Criteria {
+		fmt.Fprintf(&sb, "- %s (%.0f%%): %s\n", c.Name, c.Weight/total*100, c.Description)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// extractSubScores finds a "<Criterion Name>: <score>/100 — <justification>"
+// line for each criterion. Criteria the model left out are marked Missing.
+func extractSubScores(text string, r *Rubric) []SubScore {
+	scores := make([]SubScore, 0, len(r.Criteria))
+	for _, c := range r.Criteria {
+		sub := SubScore{Key: c.Key, Name: c.Name, Weight: c.Weight, Missing: true}
+
+		re := regexp.MustCompile(`(?im)^[\s\-*•]*` + regexp.QuoteMeta(c.Name) + `\s*:\s*(\d+(?:\.\d+)?)\s*(?:/\s*100)?\s*(?:[-—–:]+\s*(.*))?$`)
+		if m := re.FindStringSubmatch(text); m != nil {
+			if f, err := strconv.ParseFloat(m[1], 64); err == nil {
+				sub.Score = clampScore(int(math.Round(f)))
+				sub.Justification = strings.TrimSpace(m[2])
+				sub.Missing = false
+			}
+		}
+		scores = append(scores, sub)
+	}
+	return scores
+}
+
+// weightedScore computes the deterministic total from the sub-scores. It
+// reports false when the model returned none of the criteria, so callers can
+// fall back to the holistic Fit Score.
+func weightedScore(subs []SubScore) (int, bool) {
+	var total, weights float64
+	found := false
+	for _, s := range subs {
+		weights += s.Weight
+		total += s.Weight * float64(s.Score)
+		if !s.Missing {
+			found = true
+		}
+	}
+	if !found || weights == 0 {
+		return 0, false
+	}
+	return int(math.Round(total / weights)), true
+}
+
+func clampScore(score int) int {
+	return max(0, min(100, score))
+}
+
+// criterionScore returns the sub-score for key, or the total for "score".
+func (e Evaluation) criterionScore(key string) int {
+	if key == "" || key == "score" {
+		return e.Score
+	}
+	for _, s := range e.SubScores {
+		if s.Key == key {
+			return s.Score
+		}
+	}
+	return -1
+}
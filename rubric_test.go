@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractSubScoresAndWeightedScore(t *testing.T) {
+	text := `Fit Score: 70/100
+
+Criteria Scores:
+- Skills Match: 80/100 — Go and Redis both appear on the resume
+- Experience Level: 50/100 - asks for 2 years, resume shows internships
+- Location/Remote: 100 — fully remote
+* Domain: 40/100: no fintech background
+`
+	subs := extractSubScores(text, &defaultRubric)
+	if len(subs) != len(defaultRubric.Criteria) {
+		t.Fatalf("expected %d sub-scores, got %d", len(defaultRubric.Criteria), len(subs))
+	}
+
+	want := map[string]int{"skills": 80, "experience": 50, "location": 100, "domain": 40, "education": 0}
+	for _, s := range subs {
+		if s.Score != want[s.Key] {
+			t.Errorf("%s: expected %d, got %d", s.Key, want[s.Key], s.Score)
+		}
+	}
+	if subs[0].Justification != "Go and Redis both appear on the resume" {
+		t.Errorf("unexpected justification %q", subs[0].Justification)
+	}
+	if !subs[4].Missing {
+		t.Error("expected education to be marked missing")
+	}
+
+	// 0.4*80 + 0.2*50 + 0.15*100 + 0.15*40 + 0.1*0 = 63
+	score, ok := weightedScore(subs)
+	if !ok || score != 63 {
+		t.Errorf("expected weighted score 63, got %d (ok=%v)", score, ok)
+	}
+}
+
+func TestWeightedScoreWithoutSubScores(t *testing.T) {
+	subs := extractSubScores("Fit Score: 55/100", &defaultRubric)
+	if _, ok := weightedScore(subs); ok {
+		t.Error("expected no weighted score when the model returned no criteria")
+	}
+}
+
+func TestSortEvaluationsByCriterion(t *testing.T) {
+	evals := []Evaluation{
+		{JobID: "a", Score: 90, SubScores: []SubScore{{Key: "location", Score: 10}}},
+		{JobID: "b", Score: 60, SubScores: []SubScore{{Key: "location", Score: 100}}},
+	}
+	sorted := sortEvaluationsBy(evals, "location")
+	if sorted[0].JobID != "b" {
+		t.Errorf("expected job b first when sorting by location, got %s", sorted[0].JobID)
+	}
+	sorted = sortEvaluationsBy(evals, "score")
+	if sorted[0].JobID != "a" {
+		t.Errorf("expected job a first when sorting by score, got %s", sorted[0].JobID)
+	}
+}
+
+func TestRubricReservesSortKeys(t *testing.T) {
+	for _, key := range []string{"score", "posted", "salary"} {
+		r := Rubric{Criteria: []Criterion{{Key: key, Name: "X", Weight: 1}}}
+		if err := r.validate(); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("criterion %q: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const salaryPromptName = "salary"
+
+const (
+	periodHour  = "hour"
+	periodDay   = "day"
+	periodWeek  = "week"
+	periodMonth = "month"
+	periodYear  = "year"
+)
+
+// Compensation is a pay range found in a job description, in the posting's
+// own currency and period, plus its annual USD equivalent for comparison.
+type Compensation struct {
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Currency     string  `json:"currency"`
+	Period       string  `json:"period"`
+	AnnualMinUSD float64 `json:"annual_min_usd"`
+	AnnualMaxUSD float64 `json:"annual_max_usd"`
+	Source       string  `json:"source"` // "regex" or "llm"
+	Raw          string  `json:"raw"`
+}
+
+func (c Compensation) String() string {
+	amount := formatAmount(c.Min)
+	if c.Max != c.Min {
+		amount += "–" + formatAmount(c.Max)
+	}
+	s := fmt.Sprintf("%s %s/%s", amount, c.Currency, c.Period)
+	if c.Currency != "USD" || c.Period != periodYear {
+		s += fmt.Sprintf(" (≈ $%s/yr)", formatAmount(c.AnnualMinUSD))
+	}
+	return s
+}
+
+func formatAmount(v float64) string {
+	if v >= 1000 {
+		return strconv.FormatFloat(math.Round(v/1000), 'f', 0, 64) + "k"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Approximate USD exchange rates. Override or extend with
+// SALARY_FX_RATES="EUR=1.08,GBP=1.27".
+var defaultFXRates = map[string]float64{
+	"USD": 1,
+	"CAD": 0.73,
+	"EUR": 1.08,
+	"GBP": 1.27,
+	"AUD": 0.66,
+	"INR": 0.012,
+}
+
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"C$":  "CAD",
+	"CA$": "CAD",
+	"A$":  "AUD",
+	"AU$": "AUD",
+	"£":   "GBP",
+	"€":   "EUR",
+	"₹":   "INR",
+}
+
+const (
+	currencyExpr = `US\$|CA?\$|AU?\$|\$|£|€|₹|USD|CAD|EUR|GBP|AUD|INR`
+	amountExpr   = `\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`
+	periodExpr   = `per\s+hour|an\s+hour|/\s*h(?:ou)?r|hourly|per\s+day|/\s*day|daily|per\s+week|/\s*w(?:ee)?k|weekly|per\s+month|/\s*mo(?:nth)?|monthly|per\s+year|per\s+annum|a\s+year|/\s*y(?:ea)?r|annually|yearly|annual`
+)
+
+var salaryPattern = regexp.MustCompile(`(?i)(` + currencyExpr + `)\s?(` + amountExpr + `)\s?([kK])?` +
+	`(?:\s*(?:-|–|—|to)\s*(?:` + currencyExpr + `)?\s?(` + amountExpr + `)\s?([kK])?)?` +
+	`\s*(USD|CAD|EUR|GBP|AUD|INR)?` +
+	`(?:\s*(` + periodExpr + `))?` +
+	`(\s*(?:million|billion|mm|bn)\b)?`)
+
+// extractCompensation finds the first plausible pay range in text. Amounts
+// followed by "million"/"billion" (funding, revenue) are ignored, as are
+// results that don't annualize to a believable salary.
+func extractCompensation(text string) (Compensation, bool) {
+	for _, m := range salaryPattern.FindAllStringSubmatch(text, -1) {
+		if m[8] != "" {
+			continue
+		}
+		c, ok := parseCompensationMatch(m)
+		if ok {
+			return c, true
+		}
+	}
+	return Compensation{}, false
+}
+
+func parseCompensationMatch(m []string) (Compensation, bool) {
+	currency := strings.ToUpper(m[6])
+	if currency == "" {
+		currency = currencySymbols[strings.ToUpper(m[1])]
+		if currency == "" {
+			currency = strings.ToUpper(m[1])
+		}
+	}
+
+	minVal, err := parseAmount(m[2])
+	if err != nil {
+		return Compensation{}, false
+	}
+	if m[3] != "" {
+		minVal *= 1000
+	}
+	maxVal := minVal
+	if m[4] != "" {
+		if maxVal, err = parseAmount(m[4]); err != nil {
+			return Compensation{}, false
+		}
+		if m[5] != "" {
+			maxVal *= 1000
+			// "$120-150k" puts the k on the upper bound only.
+			if m[3] == "" && minVal < 1000 {
+				minVal *= 1000
+			}
+		}
+	}
+	if maxVal < minVal {
+		minVal, maxVal = maxVal, minVal
+	}
+
+	period := parsePeriod(m[7])
+	if period == "" {
+		period = inferPeriod(minVal)
+	}
+
+	c := Compensation{
+		Min:      minVal,
+		Max:      maxVal,
+		Currency: currency,
+		Period:   period,
+		Source:   "regex",
+		Raw:      strings.TrimSpace(m[0]),
+	}
+	if !annualize(&c) {
+		return Compensation{}, false
+	}
+	if c.AnnualMaxUSD < 5_000 || c.AnnualMinUSD > 2_000_000 {
+		return Compensation{}, false
+	}
+	return c, true
+}
+
+func parseAmount(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+}
+
+func parsePeriod(s string) string {
+	s = strings.ToLower(s)
+	switch {
+	case s == "":
+		return ""
+	case strings.Contains(s, "mo"):
+		return periodMonth
+	case strings.Contains(s, "h"):
+		return periodHour
+	case strings.Contains(s, "da"):
+		return periodDay
+	case strings.Contains(s, "w"):
+		return periodWeek
+	default:
+		return periodYear
+	}
+}
+
+// inferPeriod guesses the period when the posting doesn't state one.
+func inferPeriod(amount float64) string {
+	switch {
+	case amount < 300:
+		return periodHour
+	case amount < 20_000:
+		return periodMonth
+	default:
+		return periodYear
+	}
+}
+
+// annualize fills in the annual USD range. It reports false for currencies
+// without a known exchange rate.
+func annualize(c *Compensation) bool {
+	rate, ok := fxRates()[c.Currency]
+	if !ok {
+		return false
+	}
+	factor := map[string]float64{
+		periodHour:  2080,
+		periodDay:   260,
+		periodWeek:  52,
+		periodMonth: 12,
+		periodYear:  1,
+	}[c.Period]
+	c.AnnualMinUSD = math.Round(c.Min * factor * rate)
+	c.AnnualMaxUSD = math.Round(c.Max * factor * rate)
+	return true
+}
+
+func fxRates() map[string]float64 {
+	rates := make(map[string]float64, len(defaultFXRates))
+	for k, v := range defaultFXRates {
+		rates[k] = v
+	}
+	for _, pair := range splitList(config.Salary.FXRates, false) {
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && f > 0 {
+			rates[strings.ToUpper(strings.TrimSpace(code))] = f
+		}
+	}
+	return rates
+}
+
+// annualMaxUSD is the top of the job's pay range, or 0 when pay is unknown.
+func (j Job) annualMaxUSD() float64 {
+	if j.Compensation == nil {
+		return 0
+	}
+	return j.Compensation.AnnualMaxUSD
+}
+
+// jobCompensation prefers compensation already attached to the description
+// (e.g. by the LLM fallback) and otherwise runs the regex extractor.
+func jobCompensation(desc JobDescription) *Compensation {
+	if desc.Compensation != nil {
+		return desc.Compensation
+	}
+	if c, ok := extractCompensation(desc.JobDescription); ok {
+		return &c
+	}
+	return nil
+}
+
+// enrichCompensation asks the model for pay details on descriptions the
+// regex couldn't handle, when salary.llm_fallback is enabled. Each answer,
+// including "none", is written back to the Redis cache and carried over from
+// the index, so a description is only asked about once.
+func enrichCompensation(ctx context.Context, redisDB *redis.Client, ix *jobIndex, descs []JobDescription) error {
+	if !config.Salary.LLMFallback {
+		return nil
+	}
+	prompt, err := loadPromptTemplate(salaryPromptName)
+	if err != nil {
+		return err
+	}
+	model := config.Ollama.Model
+
+	for i := range descs {
+		d := &descs[i]
+		if d.SalaryChecked || jobCompensation(*d) != nil {
+			continue
+		}
+		if known := ix.checkedSalary(*d); known != nil {
+			d.Compensation, d.SalaryChecked = known.Compensation, true
+		} else {
+			c, err := extractCompensationWithLLM(ctx, prompt, model, *d)
+			if err != nil {
+				logStage(stageLLM).Warn("Salary fallback failed", "job_id", d.JobID, "model", model, "err", err)
+				continue
+			}
+			d.Compensation, d.SalaryChecked = c, true
+		}
+		if err := updateCachedDescription(ctx, redisDB, *d); err != nil {
+			logStage(stageCache).Warn("Failed to cache salary", "job_id", d.JobID, "err", err)
+		}
+	}
+	return nil
+}
+
+func extractCompensationWithLLM(ctx context.Context, prompt *PromptTemplate, model string, desc JobDescription) (*Compensation, error) {
+	text, err := prompt.Render(PromptData{Job: newPromptJob(desc)})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := talkToOllama(ctx, ollamaChatURL(), Request{
+		Model:    model,
+		Stream:   false,
+		Messages: []Message{{Role: "user", Content: text}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	answer := cleanResponse(resp)
+	if strings.Contains(strings.ToLower(answer), "salary: none") {
+		return nil, nil
+	}
+	c, ok := extractCompensation(answer)
+	if !ok {
+		return nil, nil
+	}
+	c.Source = "llm"
+	return &c, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExtractCompensation(t *testing.T) {
+	cases := []struct {
+		text                 string
+		min, max             float64
+		currency, period     string
+		annualMin, annualMax float64
+	}{
+		{"The base pay range is $120,000 - $150,000 per year.", 120000, 150000, "USD", periodYear, 120000, 150000},
+		{"Compensation: $120k-$150k", 120000, 150000, "USD", periodYear, 120000, 150000},
+		{"Pay: $120-150k plus equity", 120000, 150000, "USD", periodYear, 120000, 150000},
+		{"Interns earn $25 - $32/hr.", 25, 32, "USD", periodHour, 52000, 66560},
+		{"Hourly rate: $40 an hour", 40, 40, "USD", periodHour, 83200, 83200},
+		{"Salary £45,000 to £55,000 per annum", 45000, 55000, "GBP", periodYear, 57150, 69850},
+		{"Stipend of €3,000 per month", 3000, 3000, "EUR", periodMonth, 38880, 38880},
+	}
+	for _, c := range cases {
+		got, ok := extractCompensation(c.text)
+		if !ok {
+			t.Errorf("%q: expected a match", c.text)
+			continue
+		}
+		if got.Min != c.min || got.Max != c.max || got.Currency != c.currency || got.Period != c.period {
+			t.Errorf("%q: got %v-%v %s/%s", c.text, got.Min, got.Max, got.Currency, got.Period)
+		}
+		if got.AnnualMinUSD != c.annualMin || got.AnnualMaxUSD != c.annualMax {
+			t.Errorf("%q: got annual USD %v-%v, want %v-%v", c.text, got.AnnualMinUSD, got.AnnualMaxUSD, c.annualMin, c.annualMax)
+		}
+	}
+}
+
+func TestExtractCompensationIgnoresFunding(t *testing.T) {
+	if c, ok := extractCompensation("Backed by $200 million in funding and $1.2 billion valuation."); ok {
+		t.Errorf("expected funding amounts to be ignored, got %+v", c)
+	}
+}
+
+func TestMinSalaryFilter(t *testing.T) {
+	f := jobFilters{MinSalary: 100000}
+	low := Job{Compensation: &Compensation{AnnualMaxUSD: 80000}}
+	high := Job{Compensation: &Compensation{AnnualMaxUSD: 130000}}
+	if f.matches(low, high.PostedAt) || !f.matches(high, high.PostedAt) || !f.matches(Job{}, high.PostedAt) {
+		t.Error("min salary filter should drop low pay and keep high or unknown pay")
+	}
+}
+
+func TestEnrichCompensationAsksOnce(t *testing.T) {
+	startDevServer(t, devServerConfig{})
+	withConfig(t, func(c *Config) { c.Salary.LLMFallback = true })
+	tracker := newUsageTracker(nil)
+	ctx := withUsageTracker(context.Background(), tracker)
+	ix := newJobIndex()
+
+	descs := []JobDescription{
+		{JobID: "new", JobDescription: "Pay is competitive."},
+		{JobID: "regex", JobDescription: "Salary: $100,000 - $120,000 per year."},
+	}
+	if err := enrichCompensation(ctx, offlineRedis(t), ix, descs); err != nil {
+		t.Fatal(err)
+	}
+	if !descs[0].SalaryChecked || descs[0].Compensation != nil || descs[1].SalaryChecked {
+		t.Fatalf("descriptions = %+v", descs)
+	}
+	ix.upsertJob(descs[0], "", nil, time.Now())
+
+	// The same description seen again, as a cache hit or fetched after the
+	// cache expired, isn't sent to the model a second time.
+	again := []JobDescription{descs[0], {JobID: "new", JobDescription: "Pay is competitive."}}
+	if err := enrichCompensation(ctx, offlineRedis(t), ix, again); err != nil {
+		t.Fatal(err)
+	}
+	if !again[1].SalaryChecked {
+		t.Error("expected the indexed answer to be reused")
+	}
+	if usage := tracker.summary(); len(usage) != 1 || usage[0].Calls != 1 {
+		t.Errorf("usage = %+v, want one salary call", usage)
+	}
+}
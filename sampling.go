@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	aggregateMedian = "median"
+	aggregateMean   = "mean"
+)
+
+// samplingConfig controls self-consistency scoring: each job is evaluated
+// Samples times on every model and the scores are aggregated.
+type samplingConfig struct {
+	Models          []string
+	Samples         int
+	Aggregate       string  // aggregateMedian or aggregateMean
+	SpreadThreshold float64 // standard deviation above which a score is flagged low-confidence
+}
+
+// loadSamplingConfig reads the eval section. Models default to
+// ollama.model.
+func loadSamplingConfig(c *Config) (samplingConfig, error) {
+	cfg := samplingConfig{
+		Models:          []string{c.Ollama.Model},
+		Samples:         c.Eval.Samples,
+		Aggregate:       c.Eval.Aggregate,
+		SpreadThreshold: c.Eval.SpreadThreshold,
+	}
+
+	if c.Eval.Models != "" {
+		cfg.Models = splitList(c.Eval.Models, false)
+		if len(cfg.Models) == 0 {
+			return cfg, fmt.Errorf("eval.models=%q lists no models", c.Eval.Models)
+		}
+	}
+	if cfg.Samples < 1 {
+		return cfg, fmt.Errorf("eval.samples must be a positive integer, got %d", cfg.Samples)
+	}
+	if cfg.Aggregate != aggregateMedian && cfg.Aggregate != aggregateMean {
+		return cfg, fmt.Errorf("eval.aggregate must be %q or %q, got %q", aggregateMedian, aggregateMean, cfg.Aggregate)
+	}
+	if cfg.SpreadThreshold < 0 {
+		return cfg, fmt.Errorf("eval.spread_threshold must not be negative, got %g", cfg.SpreadThreshold)
+	}
+	return cfg, nil
+}
+
+// evaluateJob renders the prompts for one job, collects every sample and
+// folds them into a single Evaluation.
+func evaluateJob(ctx context.Context, s evalSettings, i int, job JobDescription) (eval Evaluation, err error) {
+	ctx, span := tracer.Start(ctx, "evaluate job", trace.WithAttributes(attribute.String("job_id", job.JobID)))
+	defer func() {
+		span.SetAttributes(attribute.Int("score", eval.Score))
+		endSpan(span, err)
+	}()
+
+	data := PromptData{
+		Resume:        s.resume,
+		CandidateName: s.candidateName,
+		Rubric:        s.rubric,
+		Examples:      s.examples,
+		Job:           newPromptJob(job),
+	}
+	system, err := s.systemPrompt.Render(data)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	prompt, err := s.evalPrompt.Render(data)
+	if err != nil {
+		return Evaluation{}, err
+	}
+
+	var samples []Evaluation
+	var lastErr error
+	for _, model := range s.sampling.Models {
+		for n := 1; n <= s.sampling.Samples; n++ {
+			lg := logStage(stageLLM).With("job_id", job.JobID, "model", model, "attempt", n)
+			sample, err := evaluateSample(ctx, s, model, system, prompt)
+			if err != nil {
+				lg.Warn("Sample failed", "err", err)
+				lastErr = err
+				continue
+			}
+			lg.Debug("Sample scored", "score", sample.RawScore, "duration_ms", sample.Latency.Milliseconds(),
+				"prompt_tokens", sample.PromptTokens, "completion_tokens", sample.CompletionTokens, "cost_usd", sample.CostUSD)
+			if cal := s.calibrations.lookup(model, s.evalPrompt.ID()); cal != nil {
+				sample.Score = cal.apply(sample.RawScore)
+				sample.Calibrated = true
+			}
+			samples = append(samples, sample)
+		}
+	}
+	if len(samples) == 0 {
+		return Evaluation{}, fmt.Errorf("no successful samples: %w", lastErr)
+	}
+
+	eval = aggregateSamples(samples, s.sampling)
+	eval.JobID = job.JobID
+	eval.Title = job.JobPosition
+	eval.Company = job.CompanyName
+	eval.ApplyLink = job.JobApplyLink
+	eval.PromptVersion = s.evalPrompt.ID()
+	eval.Job = normalizeJob(job)
+	eval.Skills = matchSkills(eval.Job.Requirements, s.resumeSkills)
+	eval.Text = fmt.Sprintf("🔽 Job Evaluation #%d\n%s\n\n", i+1, eval.Text)
+	return eval, nil
+}
+
+// aggregateSamples combines the total and each sub-score across samples and
+// keeps the text of the sample closest to the aggregate as the explanation.
+func aggregateSamples(samples []Evaluation, cfg samplingConfig) Evaluation {
+	totals := make([]float64, len(samples))
+	raws := make([]float64, len(samples))
+	modelScores := make([]float64, len(samples))
+	models := map[string]bool{}
+	var modelNames []string
+	for i, s := range samples {
+		totals[i] = float64(s.Score)
+		raws[i] = float64(s.RawScore)
+		modelScores[i] = float64(s.ModelScore)
+		if !models[s.Model] {
+			models[s.Model] = true
+			modelNames = append(modelNames, s.Model)
+		}
+	}
+
+	agg := aggregate(totals, cfg.Aggregate)
+	representative := samples[0]
+	for _, s := range samples[1:] {
+		if math.Abs(float64(s.Score)-agg) < math.Abs(float64(representative.Score)-agg) {
+			representative = s
+		}
+	}
+
+	eval := Evaluation{
+		Model:      strings.Join(modelNames, ", "),
+		Score:      clampScore(int(math.Round(agg))),
+		RawScore:   clampScore(int(math.Round(aggregate(raws, cfg.Aggregate)))),
+		Calibrated: slices.ContainsFunc(samples, func(s Evaluation) bool { return s.Calibrated }),
+		ModelScore: clampScore(int(math.Round(aggregate(modelScores, cfg.Aggregate)))),
+		SubScores:  aggregateSubScores(samples, cfg.Aggregate),
+		Text:       representative.Text,
+		Samples:    len(samples),
+		Spread:     stddev(totals),
+		MinScore:   int(slices.Min(totals)),
+		MaxScore:   int(slices.Max(totals)),
+	}
+	eval.LowConfidence = len(samples) > 1 && eval.Spread > cfg.SpreadThreshold
+	for _, s := range samples {
+		eval.PromptTokens += s.PromptTokens
+		eval.CompletionTokens += s.CompletionTokens
+		eval.Latency += s.Latency
+		eval.GenerationTime += s.GenerationTime
+		eval.CostUSD += s.CostUSD
+		eval.FormatErrors += s.FormatErrors
+	}
+	return eval
+}
+
+func aggregateSubScores(samples []Evaluation, method string) []SubScore {
+	if len(samples[0].SubScores) == 0 {
+		return nil
+	}
+
+	subs := make([]SubScore, len(samples[0].SubScores))
+	copy(subs, samples[0].SubScores)
+	for c := range subs {
+		var values []float64
+		for _, s := range samples {
+			if c < len(s.SubScores) && !s.SubScores[c].Missing {
+				values = append(values, float64(s.SubScores[c].Score))
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		subs[c].Score = clampScore(int(math.Round(aggregate(values, method))))
+		subs[c].Missing = false
+		if subs[c].Justification == "" {
+			for _, s := range samples {
+				if j := s.SubScores[c].Justification; j != "" {
+					subs[c].Justification = j
+					break
+				}
+			}
+		}
+	}
+	return subs
+}
+
+func aggregate(values []float64, method string) float64 {
+	if method == aggregateMean {
+		return mean(values)
+	}
+	return median(values)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// stddev is the population standard deviation.
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestAggregateSamples(t *testing.T) {
+	samples := []Evaluation{
+		{Model: "a", Score: 40, ModelScore: 50, Text: "low"},
+		{Model: "a", Score: 70, ModelScore: 70, Text: "middle"},
+		{Model: "b", Score: 90, ModelScore: 80, Text: "high"},
+	}
+	cfg := samplingConfig{Aggregate: aggregateMedian, SpreadThreshold: 10}
+
+	eval := aggregateSamples(samples, cfg)
+	if eval.Score != 70 {
+		t.Errorf("expected median 70, got %d", eval.Score)
+	}
+	if eval.Text != "middle" {
+		t.Errorf("expected text from the sample closest to the median, got %q", eval.Text)
+	}
+	if eval.Model != "a, b" {
+		t.Errorf("expected both models listed, got %q", eval.Model)
+	}
+	if eval.MinScore != 40 || eval.MaxScore != 90 || eval.Samples != 3 {
+		t.Errorf("unexpected range %d-%d over %d samples", eval.MinScore, eval.MaxScore, eval.Samples)
+	}
+	if !eval.LowConfidence {
+		t.Errorf("expected spread %.1f to be flagged low-confidence", eval.Spread)
+	}
+
+	cfg.Aggregate = aggregateMean
+	if got := aggregateSamples(samples, cfg).Score; got != 67 {
+		t.Errorf("expected mean 67, got %d", got)
+	}
+}
+
+func TestAggregateSingleSampleIsConfident(t *testing.T) {
+	eval := aggregateSamples([]Evaluation{{Score: 55}}, samplingConfig{Aggregate: aggregateMedian})
+	if eval.LowConfidence || eval.Spread != 0 || eval.Score != 55 {
+		t.Errorf("unexpected single-sample aggregate %+v", eval)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// secretProvider resolves the part of a secret reference after its scheme,
+// e.g. "/run/secrets/scrapingdog" for "file:/run/secrets/scrapingdog".
+type secretProvider func(ref string) (string, error)
+
+// secretProviders are the schemes a secret setting may use instead of a
+// literal value. Tests replace "cmd" so they don't need a password manager.
+var secretProviders = map[string]secretProvider{
+	"env":  envSecret,
+	"file": fileSecret,
+	"cmd":  commandSecret,
+}
+
+// secretCommandTimeout bounds how long a cmd: provider may take, so a
+// password manager waiting on a PIN prompt doesn't hang the run.
+const secretCommandTimeout = 10 * time.Second
+
+func envSecret(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+// fileSecret reads a secret from a file, such as a Docker or Kubernetes
+// secret mount. A trailing newline is not part of the secret.
+func fileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimRight(string(data), "\r\n")
+	if v == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return v, nil
+}
+
+// commandSecret runs a command such as `pass show scrapingdog` and uses the
+// first line of its output, which is where pass keeps the password. The
+// command is split on spaces and run directly, not through a shell.
+func commandSecret(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return "", fmt.Errorf("%s printed nothing", args[0])
+	}
+	return line, nil
+}
+
+// secretRef splits "scheme:ref" when scheme is a known provider. Anything
+// else, including a key that happens to contain a colon, is a literal.
+func secretRef(value string) (scheme, ref string, ok bool) {
+	scheme, ref, ok = strings.Cut(value, ":")
+	if !ok {
+		return "", "", false
+	}
+	if _, known := secretProviders[scheme]; !known {
+		return "", "", false
+	}
+	return scheme, strings.TrimSpace(ref), true
+}
+
+// resolveSecrets replaces provider references in secret settings with the
+// values they point to, and registers every secret with the log redactor.
+func (c *Config) resolveSecrets() []string {
+	var problems []string
+	for _, f := range c.fields() {
+		if !f.Secret {
+			continue
+		}
+		scheme, ref, ok := secretRef(f.String())
+		if !ok {
+			addSecret(f.String())
+			continue
+		}
+		v, err := secretProviders[scheme](ref)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s provider: %v", f.Key, scheme, err))
+			f.value.SetString("")
+			continue
+		}
+		f.value.SetString(v)
+		addSecret(v)
+		c.sources[f.Key] += " via " + scheme
+	}
+	return problems
+}
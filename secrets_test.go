@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "scrapingdog")
+	os.WriteFile(keyFile, []byte("sd-from-file\n"), 0600)
+	redisFile := filepath.Join(dir, "redis")
+	os.WriteFile(redisFile, []byte("redis-from-docker\n"), 0600)
+
+	embedFile := filepath.Join(dir, "embed")
+	os.WriteFile(embedFile, []byte("embed-from-docker\n"), 0600)
+
+	old := secretProviders["cmd"]
+	secretProviders["cmd"] = func(ref string) (string, error) {
+		switch ref {
+		case "pass show smtp":
+			return "smtp-from-pass", nil
+		case "pass show feedback":
+			return "feedback-from-pass", nil
+		}
+		return "", errors.New("unexpected command " + ref)
+	}
+	t.Cleanup(func() { secretProviders["cmd"] = old })
+
+	t.Setenv("SCRAPINGDOG_API_KEY", "file:"+keyFile)
+	t.Setenv("REDIS_PASSWORD_FILE", redisFile)
+	t.Setenv("EMAIL_PASSWORD", "cmd: pass show smtp")
+	t.Setenv("EMBED_API_KEY_FILE", embedFile)
+	t.Setenv("FEEDBACK_SECRET", "cmd:pass show feedback")
+
+	c, err := loadConfig(configSources{File: filepath.Join(dir, "none.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ScrapingDog.APIKey != "sd-from-file" || c.Redis.Password != "redis-from-docker" || c.Email.Password != "smtp-from-pass" {
+		t.Errorf("got %q, %q, %q", c.ScrapingDog.APIKey, c.Redis.Password, c.Email.Password)
+	}
+	if c.Embed.APIKey != "embed-from-docker" || c.Feedback.Secret != "feedback-from-pass" {
+		t.Errorf("got %q, %q", c.Embed.APIKey, c.Feedback.Secret)
+	}
+	if c.sources["redis.password"] != "env REDIS_PASSWORD_FILE via file" {
+		t.Errorf("source = %q", c.sources["redis.password"])
+	}
+	if got := redactSecrets("key sd-from-file, pass smtp-from-pass, embed-from-docker, feedback-from-pass"); strings.Contains(got, "from") {
+		t.Errorf("resolved secrets not registered for redaction: %q", got)
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	t.Setenv("SCRAPINGDOG_API_KEY", "file:"+filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("EMAIL_PASSWORD", "env:NO_SUCH_SECRET_VAR")
+	os.Unsetenv("NO_SUCH_SECRET_VAR")
+
+	_, err := loadConfig(configSources{File: filepath.Join(t.TempDir(), "none.json")})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"scrapingdog.api_key: file provider", "email.password: env provider: environment variable NO_SUCH_SECRET_VAR is not set"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error is missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestSecretRefLiterals(t *testing.T) {
+	for _, v := range []string{"plain-key", "https://example.com", "abc:def"} {
+		if _, _, ok := secretRef(v); ok {
+			t.Errorf("%q treated as a reference", v)
+		}
+	}
+	if scheme, ref, ok := secretRef("cmd:pass show x"); !ok || scheme != "cmd" || ref != "pass show x" {
+		t.Errorf("got %q %q %v", scheme, ref, ok)
+	}
+}
+
+func TestCommandSecret(t *testing.T) {
+	got, err := commandSecret("printf secret-line\\nsecond")
+	if err != nil {
+		t.Skip("printf not available:", err)
+	}
+	if got != "secret-line" {
+		t.Errorf("got %q, want the first line", got)
+	}
+	if _, err := commandSecret("false"); err == nil {
+		t.Error("expected an error from a failing command")
+	}
+}
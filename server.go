@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// runServeCommand starts the HTTP API and pages over the local job history.
+func runServeCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address; the tracker and feedback routes are unauthenticated, so keep it on localhost or behind an authenticating proxy")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	repo, err := openRepository(context.Background())
+	if err != nil {
+		return err
+	}
+	defer repo.close()
+
+	slog.Info("Serving API", "addr", *addr)
+	return http.ListenAndServe(*addr, newServeMux(repo))
+}
+
+// apiServer serves the API and pages from one repository, opened for the
+// life of the server.
+type apiServer struct {
+	repo repository
+}
+
+func newServeMux(repo repository) *http.ServeMux {
+	s := &apiServer{repo: repo}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("GET /trends", s.handleTrends)
+	mux.HandleFunc("GET /trends.csv", s.handleTrendsCSV)
+	mux.HandleFunc("GET /api/applications", s.handleListApplications)
+	mux.HandleFunc("POST /api/applications/{id}", s.handleUpdateApplication)
+	mux.HandleFunc("GET /tracker", s.handleTrackerPage)
+	mux.HandleFunc("POST /tracker/{id}", s.handleTrackerForm)
+	mux.HandleFunc("GET /feedback/{id}", handleFeedbackLink)
+	mux.HandleFunc("POST /feedback/{id}", s.handleFeedbackForm)
+	mux.HandleFunc("POST /api/feedback/{id}", s.handleFeedbackAPI)
+	mux.HandleFunc("GET /api/jobs/{id}", s.handleStoredJob)
+	mux.HandleFunc("GET /api/runs", s.handleRuns)
+	mux.Handle("GET /metrics", metricsHandler())
+	return mux
+}
+
+// storeErrorStatus is 404 for a job that isn't stored or can't take
+// feedback yet, and 500 for anything else.
+func storeErrorStatus(err error) int {
+	if errors.Is(err, errJobNotStored) || errors.Is(err, errNotEvaluated) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// handleSearch serves GET /api/search?q=<text>&n=<limit>.
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+	limit := 10
+	if n := r.URL.Query().Get("n"); n != "" {
+		v, err := strconv.Atoi(n)
+		if err != nil || v < 1 {
+			writeJSONError(w, http.StatusBadRequest, "n must be a positive integer")
+			return
+		}
+		limit = v
+	}
+
+	results, err := searchJobIndex(r.Context(), s.repo, query, limit)
+	if err != nil {
+		slog.Error("Search failed", "query", query, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleTrends serves the trend dashboard. It takes the same since, from and
+// to parameters as the trends command.
+func (s *apiServer) handleTrends(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.trendReportForRequest(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(trendDashboardHTML(report)))
+}
+
+// handleTrendsCSV serves one trend table as CSV, chosen with ?table=.
+func (s *apiServer) handleTrendsCSV(w http.ResponseWriter, r *http.Request) {
+	table := r.URL.Query().Get("table")
+	if table == "" {
+		table = "daily"
+	}
+	if !slices.Contains(trendCSVTables, table) {
+		writeJSONError(w, http.StatusBadRequest, "unknown table "+table)
+		return
+	}
+	report, ok := s.trendReportForRequest(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+table+".csv")
+	if err := writeTrendCSV(w, report, table); err != nil {
+		slog.Error("Failed to write trend CSV", "err", err)
+	}
+}
+
+func (s *apiServer) trendReportForRequest(w http.ResponseWriter, r *http.Request) (trendReport, bool) {
+	q := r.URL.Query()
+	var since time.Duration
+	if v := q.Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid since duration")
+			return trendReport{}, false
+		}
+		since = d
+	}
+	from, to, err := parseDateRange(since, q.Get("from"), q.Get("to"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return trendReport{}, false
+	}
+	report, err := loadTrendReport(r.Context(), s.repo, from, to)
+	if err != nil {
+		slog.Error("Failed to build trend report", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return trendReport{}, false
+	}
+	return report, true
+}
+
+// handleFeedbackLink serves GET /feedback/{id}?kind=<kind>&sig=<sig>, the
+// links in the emailed report and on the tracker page. It only asks for
+// confirmation: mail scanners and link prefetchers follow GETs, so the vote
+// itself is a POST.
+func handleFeedbackLink(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	kind, err := parseFeedbackKind(r.URL.Query().Get("kind"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sig := r.URL.Query().Get("sig")
+	if !validFeedbackSignature(jobID, kind, sig) {
+		http.Error(w, "invalid feedback link", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><html><body style='font-family:sans-serif;padding:20px;'><form method='post'>"+
+		"<p>Record <b>%s</b> for job %s?</p><input type='hidden' name='kind' value='%s'><input type='hidden' name='sig' value='%s'>"+
+		"<p><input name='note' placeholder='Note (optional)' size='40'></p><button type='submit'>Record feedback</button></form></body></html>",
+		html.EscapeString(feedbackLabels[kind]), html.EscapeString(jobID), html.EscapeString(string(kind)), html.EscapeString(sig))
+}
+
+// handleFeedbackForm serves POST /feedback/{id}, the confirmation form.
+func (s *apiServer) handleFeedbackForm(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	kind, err := parseFeedbackKind(r.PostFormValue("kind"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validFeedbackSignature(jobID, kind, r.PostFormValue("sig")) {
+		http.Error(w, "invalid feedback link", http.StatusForbidden)
+		return
+	}
+	if _, err := recordFeedback(r.Context(), s.repo, jobID, kind, r.PostFormValue("note")); err != nil {
+		http.Error(w, redactSecrets(err.Error()), storeErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><html><body style='font-family:sans-serif;padding:20px;'><p>Thanks! Recorded <b>%s</b> for job %s.</p><p><a href='/tracker'>Back to the tracker</a></p></body></html>",
+		html.EscapeString(feedbackLabels[kind]), html.EscapeString(jobID))
+}
+
+// handleFeedbackAPI serves POST /api/feedback/{id} with a JSON body of
+// {"kind": "...", "note": "...", "sig": "..."}. The signature is the same
+// one the report's links carry.
+func (s *apiServer) handleFeedbackAPI(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Kind string `json:"kind"`
+		Note string `json:"note"`
+		Sig  string `json:"sig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	kind, err := parseFeedbackKind(req.Kind)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !validFeedbackSignature(r.PathValue("id"), kind, req.Sig) {
+		writeJSONError(w, http.StatusForbidden, "invalid feedback signature")
+		return
+	}
+	fb, err := recordFeedback(r.Context(), s.repo, r.PathValue("id"), kind, req.Note)
+	if err != nil {
+		writeJSONError(w, storeErrorStatus(err), redactSecrets(err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, fb)
+}
+
+// handleStoredJob serves GET /api/jobs/{id}: the listing, description,
+// evaluations and feedback stored for one job.
+func (s *apiServer) handleStoredJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.repo.job(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, storeErrorStatus(err), redactSecrets(err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleRuns serves GET /api/runs?n=<limit>, most recent first.
+func (s *apiServer) handleRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if n := r.URL.Query().Get("n"); n != "" {
+		v, err := strconv.Atoi(n)
+		if err != nil || v < 1 {
+			writeJSONError(w, http.StatusBadRequest, "n must be a positive integer")
+			return
+		}
+		limit = v
+	}
+
+	runs, err := s.repo.runs(r.Context(), limit)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, redactSecrets(err.Error()))
+		return
+	}
+	if runs == nil {
+		runs = []runRecord{}
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write JSON response", "err", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
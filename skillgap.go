@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Required skills count double when measuring how well a resume covers a job.
+const (
+	requiredSkillWeight  = 2
+	preferredSkillWeight = 1
+)
+
+// skillGapSample is one job as seen by the skill-gap analysis. Score is nil
+// for jobs that never received a full evaluation.
+type skillGapSample struct {
+	Requirements Requirements
+	Match        SkillMatch
+	Score        *int
+}
+
+type skillStat struct {
+	Skill         string
+	Requested     int     // jobs listing the skill as required or preferred
+	Required      int     // jobs listing it as required
+	Missing       int     // requesting jobs where the resume lacks it
+	EstimatedLift float64 // estimated rise in average score if the resume had it
+}
+
+type skillGapReport struct {
+	Jobs              int
+	ScoredJobs        int
+	AverageScore      float64
+	PointsPerCoverage float64 // fitted score change from 0% to 100% skill coverage
+	Skills            []skillStat
+}
+
+// skillCoverage is the weighted share of a job's listed skills the resume
+// has. Jobs that list no skills report ok=false.
+func skillCoverage(req Requirements, m SkillMatch) (float64, bool) {
+	total := requiredSkillWeight*len(req.RequiredSkills) + preferredSkillWeight*len(req.PreferredSkills)
+	if total == 0 {
+		return 0, false
+	}
+	have := requiredSkillWeight*(len(req.RequiredSkills)-len(m.MissingRequired)) +
+		preferredSkillWeight*(len(req.PreferredSkills)-len(m.MissingPreferred))
+	return float64(have) / float64(total), true
+}
+
+// analyzeSkillGaps counts how often each skill is requested and missing, and
+// estimates what adding it to the resume would be worth. The estimate fits a
+// line of score against skill coverage across the scored jobs, then applies
+// that slope to the coverage each missing skill would add. It's a rough
+// guide to what to learn next, not a prediction of the model's output.
+func analyzeSkillGaps(samples []skillGapSample) skillGapReport {
+	report := skillGapReport{Jobs: len(samples)}
+
+	var scores, xs, ys []float64
+	for _, s := range samples {
+		if s.Score == nil {
+			continue
+		}
+		scores = append(scores, float64(*s.Score))
+		if cov, ok := skillCoverage(s.Requirements, s.Match); ok {
+			xs = append(xs, cov)
+			ys = append(ys, float64(*s.Score))
+		}
+	}
+	report.ScoredJobs = len(scores)
+	report.AverageScore = mean(scores)
+	report.PointsPerCoverage = math.Max(0, linearSlope(xs, ys))
+
+	stats := map[string]*skillStat{}
+	stat := func(skill string) *skillStat {
+		if st, ok := stats[skill]; ok {
+			return st
+		}
+		st := &skillStat{Skill: skill}
+		stats[skill] = st
+		return st
+	}
+	for _, s := range samples {
+		total := requiredSkillWeight*len(s.Requirements.RequiredSkills) + preferredSkillWeight*len(s.Requirements.PreferredSkills)
+		for _, skill := range s.Requirements.RequiredSkills {
+			st := stat(skill)
+			st.Requested++
+			st.Required++
+		}
+		for _, skill := range s.Requirements.PreferredSkills {
+			stat(skill).Requested++
+		}
+		for _, skill := range s.Match.MissingRequired {
+			st := stat(skill)
+			st.Missing++
+			if s.Score != nil && report.ScoredJobs > 0 {
+				st.EstimatedLift += report.PointsPerCoverage * requiredSkillWeight / float64(total) / float64(report.ScoredJobs)
+			}
+		}
+		for _, skill := range s.Match.MissingPreferred {
+			st := stat(skill)
+			st.Missing++
+			if s.Score != nil && report.ScoredJobs > 0 {
+				st.EstimatedLift += report.PointsPerCoverage * preferredSkillWeight / float64(total) / float64(report.ScoredJobs)
+			}
+		}
+	}
+
+	for _, st := range stats {
+		report.Skills = append(report.Skills, *st)
+	}
+	sortSkillStats(report.Skills, "requested")
+	return report
+}
+
+// linearSlope is the least-squares slope of ys against xs, or 0 when there
+// are too few points or no spread in xs.
+func linearSlope(xs, ys []float64) float64 {
+	if len(xs) < 3 {
+		return 0
+	}
+	mx, my := mean(xs), mean(ys)
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - mx) * (ys[i] - my)
+		den += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+func sortSkillStats(stats []skillStat, by string) {
+	key := func(s skillStat) float64 {
+		switch by {
+		case "missing":
+			return float64(s.Missing)
+		case "lift":
+			return s.EstimatedLift
+		default:
+			return float64(s.Requested)
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if ki, kj := key(stats[i]), key(stats[j]); ki != kj {
+			return ki > kj
+		}
+		if stats[i].Requested != stats[j].Requested {
+			return stats[i].Requested > stats[j].Requested
+		}
+		return stats[i].Skill < stats[j].Skill
+	})
+}
+
+// skillGapSamplesFromEvaluations uses the skills recorded on a run's
+// evaluations. Triage-only results are counted but not scored, since triage
+// scores aren't on the same scale as full evaluations.
+func skillGapSamplesFromEvaluations(evals []Evaluation) []skillGapSample {
+	samples := make([]skillGapSample, 0, len(evals))
+	for _, e := range evals {
+		s := skillGapSample{Requirements: e.Job.Requirements, Match: e.Skills}
+		if !e.TriageOnly {
+			score := e.Score
+			s.Score = &score
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// skillGapSamplesFromIndex re-derives requirements and matches for every job
+// first seen in [from, to), so the analysis reflects the current resume.
+func skillGapSamplesFromIndex(ix *jobIndex, resumeSkills []string, from, to time.Time) []skillGapSample {
+	var samples []skillGapSample
+	for _, job := range ix.Jobs {
+		if job.FirstSeen.Before(from) || (!to.IsZero() && !job.FirstSeen.Before(to)) {
+			continue
+		}
+		req := extractRequirements(job.Description.JobDescription)
+		s := skillGapSample{Requirements: req, Match: matchSkills(req, resumeSkills)}
+		if eval := job.scoredEvaluation(); eval != nil {
+			score := eval.Score
+			s.Score = &score
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// runSkillsCommand prints the skill-gap report over the job history.
+func runSkillsCommand(args []string) error {
+	fs := flag.NewFlagSet("skills", flag.ContinueOnError)
+	since := fs.Duration("since", 0, "only jobs first seen within this long ago, e.g. 720h")
+	fromFlag := fs.String("from", "", "only jobs first seen on or after this date (YYYY-MM-DD)")
+	toFlag := fs.String("to", "", "only jobs first seen before this date (YYYY-MM-DD)")
+	by := fs.String("by", "requested", "sort by requested, missing or lift")
+	limit := fs.Int("n", 20, "number of skills to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *by != "requested" && *by != "missing" && *by != "lift" {
+		return fmt.Errorf("-by must be requested, missing or lift, got %q", *by)
+	}
+
+	from, to, err := parseDateRange(*since, *fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	resume, err := os.ReadFile(config.Resume.File)
+	if err != nil {
+		return err
+	}
+	ix, err := loadStoredJobIndex(context.Background())
+	if err != nil {
+		return err
+	}
+	samples := skillGapSamplesFromIndex(ix, findSkills(string(resume)), from, to)
+	if len(samples) == 0 {
+		return errors.New("no jobs in the history for that date range")
+	}
+
+	report := analyzeSkillGaps(samples)
+	sortSkillStats(report.Skills, *by)
+	fmt.Printf("%d jobs, %d scored, average score %.1f\n\n", report.Jobs, report.ScoredJobs, report.AverageScore)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SKILL\tREQUESTED\tREQUIRED\tMISSING\tEST. LIFT")
+	for i, s := range report.Skills {
+		if i >= *limit {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%+.1f\n", s.Skill, s.Requested, s.Required, s.Missing, s.EstimatedLift)
+	}
+	return w.Flush()
+}
+
+// skillGapHTML summarizes the run's skill gaps at the end of the report.
+func skillGapHTML(report skillGapReport, limit int) string {
+	var missing []skillStat
+	for _, s := range report.Skills {
+		if s.Missing > 0 {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	sortSkillStats(missing, "lift")
+	if len(missing) > limit {
+		missing = missing[:limit]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<h2>Skill Gaps This Run</h2><table class='breakdown'><tr><th>Skill</th><th>Requested</th><th>Missing</th><th>Est. Score Lift</th></tr>")
+	for _, s := range missing {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d/%d</td><td>%d</td><td>%+.1f</td></tr>", html.EscapeString(s.Skill), s.Requested, report.Jobs, s.Missing, s.EstimatedLift))
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
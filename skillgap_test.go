@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSkillCoverage(t *testing.T) {
+	req := Requirements{RequiredSkills: []string{"Go", "Docker"}, PreferredSkills: []string{"AWS"}}
+	got, ok := skillCoverage(req, SkillMatch{MissingRequired: []string{"Docker"}, MissingPreferred: []string{"AWS"}})
+	if !ok || math.Abs(got-0.4) > 1e-9 {
+		t.Errorf("skillCoverage = %v, %v; want 0.4", got, ok)
+	}
+	if _, ok := skillCoverage(Requirements{}, SkillMatch{}); ok {
+		t.Error("expected no coverage for a job without listed skills")
+	}
+}
+
+func TestAnalyzeSkillGaps(t *testing.T) {
+	score := func(v int) *int { return &v }
+	req := Requirements{RequiredSkills: []string{"Go", "Kubernetes"}}
+	samples := []skillGapSample{
+		// Full coverage scores 90, half coverage 50: 80 points per unit of coverage.
+		{Requirements: req, Match: SkillMatch{Matched: []string{"Go", "Kubernetes"}}, Score: score(90)},
+		{Requirements: req, Match: SkillMatch{Matched: []string{"Go"}, MissingRequired: []string{"Kubernetes"}}, Score: score(50)},
+		{Requirements: req, Match: SkillMatch{Matched: []string{"Go"}, MissingRequired: []string{"Kubernetes"}}, Score: score(50)},
+		{Requirements: Requirements{PreferredSkills: []string{"Rust"}}, Match: SkillMatch{MissingPreferred: []string{"Rust"}}},
+	}
+
+	report := analyzeSkillGaps(samples)
+	if report.Jobs != 4 || report.ScoredJobs != 3 {
+		t.Fatalf("Jobs/ScoredJobs = %d/%d, want 4/3", report.Jobs, report.ScoredJobs)
+	}
+	if math.Abs(report.PointsPerCoverage-80) > 1e-9 {
+		t.Errorf("PointsPerCoverage = %v, want 80", report.PointsPerCoverage)
+	}
+
+	stats := map[string]skillStat{}
+	for _, s := range report.Skills {
+		stats[s.Skill] = s
+	}
+	if report.Skills[0].Skill != "Go" && report.Skills[0].Skill != "Kubernetes" {
+		t.Errorf("expected most requested skill first, got %q", report.Skills[0].Skill)
+	}
+	k := stats["Kubernetes"]
+	if k.Requested != 3 || k.Required != 3 || k.Missing != 2 {
+		t.Errorf("Kubernetes stats = %+v", k)
+	}
+	// Two of three scored jobs would gain half their coverage: 2 * 40 / 3.
+	if math.Abs(k.EstimatedLift-80.0/3) > 1e-9 {
+		t.Errorf("Kubernetes lift = %v, want %v", k.EstimatedLift, 80.0/3)
+	}
+	if r := stats["Rust"]; r.Missing != 1 || r.EstimatedLift != 0 {
+		t.Errorf("unscored job should count as missing without lift, got %+v", r)
+	}
+}
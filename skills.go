@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// skillNode is one entry in the skills graph: a canonical name, the spellings
+// that refer to it, and the skills it implies (knowing TypeScript covers a
+// JavaScript requirement).
+type skillNode struct {
+	Name    string
+	Aliases []string
+	Implies []string
+	pattern *regexp.Regexp
+}
+
+// Aliases are matched case-insensitively on word boundaries, except where a
+// name is also an ordinary English word ("Go", "Rust", "React"); those use
+// case-sensitive aliases marked with a leading "=", or only multi-word forms.
+var defaultSkillGraph = []skillNode{
+	{Name: "Go", Aliases: []string{"golang", "=Go"}},
+	{Name: "Rust", Aliases: []string{"=Rust"}},
+	{Name: "Python", Aliases: []string{"python"}},
+	{Name: "Java", Aliases: []string{"java"}},
+	{Name: "Kotlin", Aliases: []string{"kotlin"}},
+	{Name: "Scala", Aliases: []string{"scala"}},
+	{Name: "C", Aliases: []string{"c programming", "=C/C++"}},
+	{Name: "C++", Aliases: []string{"c++", "cpp"}},
+	{Name: "C#", Aliases: []string{"c#", ".net", "dotnet"}},
+	{Name: "JavaScript", Aliases: []string{"javascript", "ecmascript", "=JS"}},
+	{Name: "TypeScript", Aliases: []string{"typescript"}, Implies: []string{"JavaScript"}},
+	{Name: "Ruby", Aliases: []string{"=Ruby"}},
+	{Name: "PHP", Aliases: []string{"php"}},
+	{Name: "Swift", Aliases: []string{"=Swift"}},
+	{Name: "SQL", Aliases: []string{"sql"}},
+	{Name: "Bash", Aliases: []string{"bash", "shell scripting"}},
+	{Name: "HTML", Aliases: []string{"html", "html5"}},
+	{Name: "CSS", Aliases: []string{"css", "css3", "sass", "tailwind"}},
+	{Name: "React", Aliases: []string{"=React", "react.js", "reactjs"}, Implies: []string{"JavaScript"}},
+	{Name: "Angular", Aliases: []string{"angular"}, Implies: []string{"TypeScript", "JavaScript"}},
+	{Name: "Vue", Aliases: []string{"vue", "vue.js", "vuejs"}, Implies: []string{"JavaScript"}},
+	{Name: "Node.js", Aliases: []string{"node.js", "nodejs"}, Implies: []string{"JavaScript"}},
+	{Name: "Django", Aliases: []string{"django"}, Implies: []string{"Python"}},
+	{Name: "Flask", Aliases: []string{"flask"}, Implies: []string{"Python"}},
+	{Name: "FastAPI", Aliases: []string{"fastapi"}, Implies: []string{"Python"}},
+	{Name: "Spring", Aliases: []string{"=Spring", "spring boot", "spring framework"}, Implies: []string{"Java"}},
+	{Name: "Rails", Aliases: []string{"rails", "ruby on rails"}, Implies: []string{"Ruby"}},
+	{Name: "PostgreSQL", Aliases: []string{"postgresql", "postgres"}, Implies: []string{"SQL"}},
+	{Name: "MySQL", Aliases: []string{"mysql"}, Implies: []string{"SQL"}},
+	{Name: "MongoDB", Aliases: []string{"mongodb", "mongo"}},
+	{Name: "Redis", Aliases: []string{"redis"}},
+	{Name: "Kafka", Aliases: []string{"kafka"}},
+	{Name: "Elasticsearch", Aliases: []string{"elasticsearch", "opensearch"}},
+	{Name: "GraphQL", Aliases: []string{"graphql"}},
+	{Name: "REST APIs", Aliases: []string{"restful", "rest api", "rest apis"}},
+	{Name: "gRPC", Aliases: []string{"grpc"}},
+	{Name: "AWS", Aliases: []string{"aws", "amazon web services", "ec2", "s3", "aws lambda"}},
+	{Name: "GCP", Aliases: []string{"gcp", "google cloud"}},
+	{Name: "Azure", Aliases: []string{"azure"}},
+	{Name: "Docker", Aliases: []string{"docker", "containerization"}},
+	{Name: "Kubernetes", Aliases: []string{"kubernetes", "k8s", "eks", "gke", "aks"}, Implies: []string{"Docker"}},
+	{Name: "Terraform", Aliases: []string{"terraform"}},
+	{Name: "CI/CD", Aliases: []string{"ci/cd", "continuous integration", "github actions", "jenkins", "gitlab ci"}},
+	{Name: "Git", Aliases: []string{"git", "github", "gitlab"}},
+	{Name: "Linux", Aliases: []string{"linux", "unix"}},
+	{Name: "Distributed Systems", Aliases: []string{"distributed systems", "distributed computing"}},
+	{Name: "Microservices", Aliases: []string{"microservices", "microservice"}},
+	{Name: "Machine Learning", Aliases: []string{"machine learning", "=ML"}},
+	{Name: "Deep Learning", Aliases: []string{"deep learning", "neural networks"}, Implies: []string{"Machine Learning"}},
+	{Name: "PyTorch", Aliases: []string{"pytorch"}, Implies: []string{"Deep Learning", "Python"}},
+	{Name: "TensorFlow", Aliases: []string{"tensorflow"}, Implies: []string{"Deep Learning", "Python"}},
+	{Name: "LLMs", Aliases: []string{"llm", "llms", "large language models", "generative ai", "genai"}, Implies: []string{"Machine Learning"}},
+	{Name: "Data Structures & Algorithms", Aliases: []string{"data structures", "algorithms"}},
+	{Name: "Object-Oriented Programming", Aliases: []string{"object-oriented", "object oriented", "oop"}},
+	{Name: "Agile", Aliases: []string{"agile", "scrum", "kanban"}},
+	{Name: "Testing", Aliases: []string{"unit testing", "unit tests", "test-driven", "tdd", "automated testing"}},
+	{Name: "Security", Aliases: []string{"cybersecurity", "application security", "information security", "appsec"}},
+	{Name: "Spark", Aliases: []string{"spark", "pyspark"}},
+	{Name: "Airflow", Aliases: []string{"airflow"}},
+	{Name: "Pandas", Aliases: []string{"pandas", "numpy"}, Implies: []string{"Python"}},
+	{Name: "Excel", Aliases: []string{"=Excel", "microsoft excel"}},
+	{Name: "Tableau", Aliases: []string{"tableau", "power bi"}},
+	{Name: "iOS", Aliases: []string{"=iOS"}},
+	{Name: "Android", Aliases: []string{"android"}},
+	{Name: "Figma", Aliases: []string{"figma"}},
+}
+
+var certificationPattern = regexp.MustCompile(`(?i)\b(AWS Certified [A-Za-z\- ]+?(?:Associate|Professional|Practitioner|Specialty)|Certified Kubernetes (?:Administrator|Application Developer)|CK[AD]|CISSP|CISM|CompTIA (?:Security|Network|A)\+|Security\+|PMP|CSM|Google Cloud Certified[A-Za-z\- ]*|Azure (?:Fundamentals|Administrator|Developer|Solutions Architect)[A-Za-z\- ]*|CPA|CFA)\b`)
+
+var yearsPattern = regexp.MustCompile(`(?i)(\d{1,2})\s*\+?\s*(?:-|–|to)?\s*(?:\d{1,2}\s*)?\+?\s*years?(?:'|’)?\s+(?:of\s+)?(?:[a-z/\-]+\s+){0,4}?experience`)
+
+var degreePatterns = []struct {
+	level   string
+	pattern *regexp.Regexp
+}{
+	{"phd", regexp.MustCompile(`(?i)\b(ph\.?d|doctorate|doctoral)\b`)},
+	{"master", regexp.MustCompile(`(?i)\b(master'?s|m\.s\.|msc|m\.sc|mba)\b`)},
+	{"bachelor", regexp.MustCompile(`(?i)\b(bachelor'?s|b\.s\.|b\.a\.|bsc|b\.sc|undergraduate degree|\bbs\b|\bba\b)`)},
+	{"associate", regexp.MustCompile(`(?i)\bassociate'?s degree\b`)},
+}
+
+var degreeLabels = map[string]string{
+	"associate": "Associate's degree",
+	"bachelor":  "Bachelor's degree",
+	"master":    "Master's degree",
+	"phd":       "PhD",
+}
+
+var requiredHeading = regexp.MustCompile(`(?i)(requirements|required|qualifications|what you('ll)? need|must[- ]haves?|you have|who you are|minimum|basic)`)
+var preferredHeading = regexp.MustCompile(`(?i)(preferred|nice[- ]to[- ]haves?|bonus|pluses|plus points|desired|ideally)`)
+var neutralHeading = regexp.MustCompile(`(?i)(responsibilities|what you('ll)? do|about (us|the role|the team)|benefits|perks|the role|compensation)`)
+var preferredSentence = regexp.MustCompile(`(?i)(preferred|is a plus|are a plus|nice to have|bonus|ideally)`)
+
+// Requirements are what a job asks for, pulled from its description text.
+type Requirements struct {
+	RequiredSkills     []string `json:"required_skills,omitempty"`
+	PreferredSkills    []string `json:"preferred_skills,omitempty"`
+	MinYearsExperience int      `json:"min_years_experience,omitempty"`
+	Degree             string   `json:"degree,omitempty"` // lowest degree level mentioned: associate, bachelor, master or phd
+	Certifications     []string `json:"certifications,omitempty"`
+}
+
+// SkillMatch compares a job's requirements against the resume's skills.
+type SkillMatch struct {
+	Matched          []string `json:"matched,omitempty"`
+	MissingRequired  []string `json:"missing_required,omitempty"`
+	MissingPreferred []string `json:"missing_preferred,omitempty"`
+}
+
+var (
+	skillGraphOnce  sync.Once
+	skillGraphNodes []skillNode
+)
+
+// skillGraph returns the skills graph, loaded on first use so
+// eval.skills_file is read after the config. A file that fails to load
+// is reported by config validation; here the defaults are used instead.
+func skillGraph() []skillNode {
+	skillGraphOnce.Do(func() {
+		nodes, err := loadSkillGraph(config.Eval.SkillsFile)
+		if err != nil {
+			slog.Warn("Using the default skills graph", "err", err)
+			nodes, _ = loadSkillGraph("")
+		}
+		skillGraphNodes = nodes
+	})
+	return skillGraphNodes
+}
+
+// loadSkillGraph compiles the default graph plus any extra skills from path,
+// one per line as "Name: alias, alias > Implied, Implied".
+func loadSkillGraph(path string) ([]skillNode, error) {
+	nodes := slices.Clone(defaultSkillGraph)
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SKILLS_FILE: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if node, ok := parseSkillLine(scanner.Text()); ok {
+				nodes = append(nodes, node)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read SKILLS_FILE: %w", err)
+		}
+	}
+
+	for i := range nodes {
+		nodes[i].pattern = compileSkillPattern(nodes[i])
+	}
+	return nodes, nil
+}
+
+func parseSkillLine(line string) (skillNode, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return skillNode{}, false
+	}
+	rest, implied, _ := strings.Cut(line, ">")
+	name, aliases, _ := strings.Cut(rest, ":")
+	node := skillNode{Name: strings.TrimSpace(name)}
+	for _, a := range strings.Split(aliases, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			node.Aliases = append(node.Aliases, a)
+		}
+	}
+	if len(node.Aliases) == 0 {
+		node.Aliases = []string{strings.ToLower(node.Name)}
+	}
+	for _, s := range strings.Split(implied, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			node.Implies = append(node.Implies, s)
+		}
+	}
+	return node, node.Name != ""
+}
+
+func compileSkillPattern(node skillNode) *regexp.Regexp {
+	var insensitive, sensitive []string
+	for _, a := range node.Aliases {
+		if strings.HasPrefix(a, "=") {
+			sensitive = append(sensitive, regexp.QuoteMeta(a[1:]))
+		} else {
+			insensitive = append(insensitive, regexp.QuoteMeta(a))
+		}
+	}
+	// \b doesn't work next to symbols like "+" or "#", so boundaries are
+	// spelled out as "not a word character".
+	const pre, post = `(?:^|[^\w])`, `(?:$|[^\w+#])`
+	var alts []string
+	if len(insensitive) > 0 {
+		alts = append(alts, `(?i:`+strings.Join(insensitive, "|")+`)`)
+	}
+	if len(sensitive) > 0 {
+		alts = append(alts, strings.Join(sensitive, "|"))
+	}
+	return regexp.MustCompile(pre + `(?:` + strings.Join(alts, "|") + `)` + post)
+}
+
+// findSkills returns the canonical skills mentioned in text, in graph order.
+func findSkills(text string) []string {
+	var found []string
+	for _, node := range skillGraph() {
+		if node.pattern.MatchString(text) {
+			found = append(found, node.Name)
+		}
+	}
+	return found
+}
+
+// expandImplied adds every skill reachable through Implies edges.
+func expandImplied(skills []string) []string {
+	out := slices.Clone(skills)
+	for i := 0; i < len(out); i++ {
+		for _, node := range skillGraph() {
+			if node.Name != out[i] {
+				continue
+			}
+			for _, implied := range node.Implies {
+				if !slices.Contains(out, implied) {
+					out = append(out, implied)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// extractRequirements splits the description into required and preferred
+// sections by their headings, then finds skills, experience, degree and
+// certifications. Skills outside any recognized section count as required
+// unless their sentence says otherwise.
+func extractRequirements(description string) Requirements {
+	var req Requirements
+
+	section := ""
+	for _, line := range strings.Split(description, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if isHeading(trimmed) {
+			switch {
+			case preferredHeading.MatchString(trimmed):
+				section = "preferred"
+			case requiredHeading.MatchString(trimmed):
+				section = "required"
+			case neutralHeading.MatchString(trimmed):
+				section = "neutral"
+			}
+		}
+
+		for _, sentence := range splitSentences(trimmed) {
+			skills := findSkills(sentence)
+			preferred := section == "preferred" || (section != "required" && preferredSentence.MatchString(sentence))
+			for _, skill := range skills {
+				if preferred {
+					appendUnique(&req.PreferredSkills, skill)
+				} else {
+					appendUnique(&req.RequiredSkills, skill)
+				}
+			}
+		}
+	}
+	// A skill listed as both required and preferred is required.
+	req.PreferredSkills = slices.DeleteFunc(req.PreferredSkills, func(s string) bool {
+		return slices.Contains(req.RequiredSkills, s)
+	})
+
+	for _, m := range yearsPattern.FindAllStringSubmatch(description, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > req.MinYearsExperience && n < 30 {
+			req.MinYearsExperience = n
+		}
+	}
+	for i := len(degreePatterns) - 1; i >= 0; i-- {
+		if degreePatterns[i].pattern.MatchString(description) {
+			req.Degree = degreePatterns[i].level
+			break
+		}
+	}
+	for _, m := range certificationPattern.FindAllString(description, -1) {
+		appendUnique(&req.Certifications, strings.TrimSpace(m))
+	}
+	return req
+}
+
+var bulletPrefix = regexp.MustCompile(`^([-•·+]|\*\s|\d+[.)]\s)`)
+var sentenceBreak = regexp.MustCompile(`[.;!?]\s+`)
+
+// isHeading treats short lines, or lines ending in a colon, as section titles.
+// List items never are, however short.
+func isHeading(line string) bool {
+	if bulletPrefix.MatchString(line) {
+		return false
+	}
+	return strings.HasSuffix(line, ":") || (len(line) < 60 && !strings.HasSuffix(line, "."))
+}
+
+func splitSentences(text string) []string {
+	return sentenceBreak.Split(text, -1)
+}
+
+func appendUnique(list *[]string, v string) {
+	if !slices.Contains(*list, v) {
+		*list = append(*list, v)
+	}
+}
+
+// matchSkills compares requirements to the resume's skills, counting skills
+// implied by the resume (React implies JavaScript) as present.
+func matchSkills(req Requirements, resumeSkills []string) SkillMatch {
+	have := expandImplied(resumeSkills)
+
+	var m SkillMatch
+	for _, s := range req.RequiredSkills {
+		if slices.Contains(have, s) {
+			m.Matched = append(m.Matched, s)
+		} else {
+			m.MissingRequired = append(m.MissingRequired, s)
+		}
+	}
+	for _, s := range req.PreferredSkills {
+		if slices.Contains(have, s) {
+			m.Matched = append(m.Matched, s)
+		} else {
+			m.MissingPreferred = append(m.MissingPreferred, s)
+		}
+	}
+	return m
+}
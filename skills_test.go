@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindSkills(t *testing.T) {
+	text := "We use Golang, TypeScript and C/C++ on AWS. Let's Go! Experience with k8s and RESTful APIs. Series C funded."
+	got := findSkills(text)
+	for _, want := range []string{"Go", "C", "C++", "TypeScript", "REST APIs", "AWS", "Kubernetes"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("findSkills missing %q, got %v", want, got)
+		}
+	}
+	for _, unwanted := range []string{"JavaScript", "Java", "Rust"} {
+		if slices.Contains(got, unwanted) {
+			t.Errorf("findSkills unexpectedly found %q", unwanted)
+		}
+	}
+	if got := findSkills("we go the extra mile with excellent rust-free bikes"); len(got) != 0 {
+		t.Errorf("expected no skills in plain English, got %v", got)
+	}
+	prose := "Security matters here: react to node failures each spring, ship lambda functions to containers, and excel at it."
+	if got := findSkills(prose); len(got) != 0 {
+		t.Errorf("expected no skills in plain English, got %v", got)
+	}
+}
+
+func TestExtractRequirements(t *testing.T) {
+	desc := `About the role
+You will build backend services in Python.
+
+Requirements:
+- 5+ years of professional software engineering experience
+- Strong PostgreSQL and Docker skills
+- Bachelor's degree in Computer Science or equivalent
+
+Nice to have:
+- Kubernetes
+- AWS Certified Solutions Architect – Associate or CKA
+- Python
+
+Experience with Kafka is a plus.`
+
+	req := extractRequirements(desc)
+	if want := []string{"Python", "PostgreSQL", "Docker"}; !slices.Equal(req.RequiredSkills, want) {
+		t.Errorf("RequiredSkills = %v, want %v", req.RequiredSkills, want)
+	}
+	if want := []string{"Kubernetes", "AWS", "Kafka"}; !slices.Equal(req.PreferredSkills, want) {
+		t.Errorf("PreferredSkills = %v, want %v", req.PreferredSkills, want)
+	}
+	if req.MinYearsExperience != 5 {
+		t.Errorf("MinYearsExperience = %d, want 5", req.MinYearsExperience)
+	}
+	if req.Degree != "bachelor" {
+		t.Errorf("Degree = %q, want bachelor", req.Degree)
+	}
+	if !slices.Contains(req.Certifications, "CKA") {
+		t.Errorf("Certifications = %v, want CKA", req.Certifications)
+	}
+}
+
+func TestMatchSkillsUsesImpliedSkills(t *testing.T) {
+	req := Requirements{
+		RequiredSkills:  []string{"JavaScript", "Go", "Docker"},
+		PreferredSkills: []string{"Python", "Terraform"},
+	}
+	got := matchSkills(req, []string{"React", "Kubernetes", "Go", "Django"})
+
+	want := SkillMatch{
+		Matched:          []string{"JavaScript", "Go", "Docker", "Python"},
+		MissingPreferred: []string{"Terraform"},
+	}
+	if !slices.Equal(got.Matched, want.Matched) || len(got.MissingRequired) != 0 || !slices.Equal(got.MissingPreferred, want.MissingPreferred) {
+		t.Errorf("matchSkills = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSkillLine(t *testing.T) {
+	node, ok := parseSkillLine("Elixir: elixir, phoenix > Erlang")
+	if !ok || node.Name != "Elixir" || !slices.Equal(node.Aliases, []string{"elixir", "phoenix"}) || !slices.Equal(node.Implies, []string{"Erlang"}) {
+		t.Errorf("parseSkillLine = %+v, %v", node, ok)
+	}
+	if _, ok := parseSkillLine("# comment"); ok {
+		t.Error("expected comment line to be skipped")
+	}
+}
+
+func TestBulletsAreNotHeadings(t *testing.T) {
+	desc := `Nice to have:
+- Basic knowledge of Kubernetes
+• Required reading: Terraform docs
+* Minimum of some Kafka
+1. Must have seen Redis`
+
+	req := extractRequirements(desc)
+	if len(req.RequiredSkills) != 0 {
+		t.Errorf("bullets switched the section to required: %v", req.RequiredSkills)
+	}
+	if want := []string{"Kubernetes", "Terraform", "Kafka", "Redis"}; !slices.Equal(req.PreferredSkills, want) {
+		t.Errorf("PreferredSkills = %v, want %v", req.PreferredSkills, want)
+	}
+	for _, heading := range []string{"Requirements", "What you'll need:", "**Basic Qualifications**"} {
+		if !isHeading(heading) {
+			t.Errorf("expected %q to be a heading", heading)
+		}
+	}
+}
+
+func TestLoadSkillGraphFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills.txt")
+	os.WriteFile(path, []byte("# extra skills\nElixir: elixir, phoenix > Erlang\n"), 0644)
+	nodes, err := loadSkillGraph(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := nodes[len(nodes)-1]; last.Name != "Elixir" || last.pattern == nil {
+		t.Errorf("last node = %+v", last)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := loadSkillGraph(missing); err == nil {
+		t.Error("expected an error for a missing skills file")
+	}
+	c := defaultConfig()
+	c.Eval.SkillsFile = missing
+	if errs := featureConfigErrors(c); len(errs) == 0 {
+		t.Error("expected config validation to report the missing skills file")
+	}
+}
@@ -0,0 +1,1093 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// repository is the relational history of everything the pipeline
+// produces: listings, descriptions, embeddings, evaluations, runs, feedback
+// and the application tracker. It is the system of record: the pipeline,
+// the API and the reports all read and write through it.
+type repository interface {
+	saveListings(ctx context.Context, listings []JobListing, seen time.Time) error
+	saveDescriptions(ctx context.Context, descs []JobDescription, seen time.Time) error
+	saveEmbedding(ctx context.Context, jobID, model string, vector []float64) error
+	saveEvaluations(ctx context.Context, runID string, evals []Evaluation, at time.Time) error
+	saveRun(ctx context.Context, run runRecord) error
+	saveFeedback(ctx context.Context, jobID string, fb Feedback) error
+	// updateApplication applies u to the job's application in one
+	// transaction, creating it if needed, or returns errJobNotStored.
+	updateApplication(ctx context.Context, jobID string, u applicationUpdate) (*Application, error)
+
+	// job returns everything stored about one job, or errJobNotStored.
+	job(ctx context.Context, jobID string) (storedJob, error)
+	// jobHistory returns every stored job with its embedding, evaluations
+	// (oldest first), feedback and application.
+	jobHistory(ctx context.Context) (map[string]*indexedJob, error)
+	// runs returns the most recent runs first; limit <= 0 means all.
+	runs(ctx context.Context, limit int) ([]runRecord, error)
+	close() error
+}
+
+var errJobNotStored = errors.New("job is not in the database")
+
+// storedJob is one job as the API returns it.
+type storedJob struct {
+	Listing     *JobListing    `json:"listing,omitempty"`
+	Description JobDescription `json:"description"`
+	FirstSeen   time.Time      `json:"first_seen"`
+	LastSeen    time.Time      `json:"last_seen"`
+	Evaluations []Evaluation   `json:"evaluations"`
+	Feedback    []Feedback     `json:"feedback"`
+	Application *Application   `json:"application,omitempty"`
+}
+
+// Databases for storage.database.
+const (
+	dbSQLite   = "sqlite"
+	dbPostgres = "postgres"
+)
+
+// sqlDialect covers the few places SQLite and Postgres disagree. Queries
+// are written with ? placeholders and rebound for Postgres; migrations use
+// {{id}} and {{time}} for the column types that differ.
+type sqlDialect struct {
+	driver    string
+	numbered  bool   // $1, $2, … placeholders
+	forUpdate string // row lock for read-modify-write; SQLite has one writer anyway
+	types     *strings.Replacer
+}
+
+var sqlDialects = map[string]sqlDialect{
+	dbSQLite: {
+		driver: "sqlite",
+		types:  strings.NewReplacer("{{id}}", "INTEGER PRIMARY KEY AUTOINCREMENT", "{{time}}", "TIMESTAMP"),
+	},
+	dbPostgres: {
+		driver:    "pgx",
+		numbered:  true,
+		forUpdate: " FOR UPDATE",
+		types:     strings.NewReplacer("{{id}}", "BIGSERIAL PRIMARY KEY", "{{time}}", "TIMESTAMPTZ"),
+	},
+}
+
+func (d sqlDialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// migration is one schema change. Applied versions are recorded in
+// schema_migrations, so each runs exactly once per database. Never edit a
+// released migration; add a new one.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+var migrations = []migration{
+	{1, "initial schema", []string{
+		`CREATE TABLE job_listings (
+			job_id           TEXT PRIMARY KEY,
+			job_position     TEXT NOT NULL,
+			job_link         TEXT NOT NULL,
+			company_name     TEXT NOT NULL,
+			company_profile  TEXT NOT NULL,
+			job_location     TEXT NOT NULL,
+			job_posting_date TEXT NOT NULL,
+			profile          TEXT NOT NULL,
+			first_seen       {{time}} NOT NULL,
+			last_seen        {{time}} NOT NULL
+		)`,
+		`CREATE TABLE job_descriptions (
+			job_id              TEXT PRIMARY KEY,
+			job_position        TEXT NOT NULL,
+			job_location        TEXT NOT NULL,
+			company_name        TEXT NOT NULL,
+			company_linkedin_id TEXT NOT NULL,
+			job_posting_time    TEXT NOT NULL,
+			job_description     TEXT NOT NULL,
+			seniority_level     TEXT NOT NULL,
+			employment_type     TEXT NOT NULL,
+			job_function        TEXT NOT NULL,
+			industries          TEXT NOT NULL,
+			job_apply_link      TEXT NOT NULL,
+			profile             TEXT NOT NULL,
+			compensation        TEXT,
+			fetched_at          {{time}} NOT NULL,
+			first_seen          {{time}} NOT NULL,
+			last_seen           {{time}} NOT NULL
+		)`,
+		`CREATE TABLE recruiters (
+			job_id TEXT NOT NULL REFERENCES job_descriptions (job_id) ON DELETE CASCADE,
+			seq    INTEGER NOT NULL,
+			name   TEXT NOT NULL,
+			title  TEXT NOT NULL,
+			PRIMARY KEY (job_id, seq)
+		)`,
+		// relation is similar or people_also_viewed.
+		`CREATE TABLE related_jobs (
+			job_id           TEXT NOT NULL REFERENCES job_descriptions (job_id) ON DELETE CASCADE,
+			relation         TEXT NOT NULL,
+			seq              INTEGER NOT NULL,
+			job_position     TEXT NOT NULL,
+			job_company      TEXT NOT NULL,
+			job_location     TEXT NOT NULL,
+			job_posting_time TEXT NOT NULL,
+			job_link         TEXT NOT NULL,
+			PRIMARY KEY (job_id, relation, seq)
+		)`,
+		`CREATE TABLE runs (
+			id          TEXT PRIMARY KEY,
+			started_at  {{time}} NOT NULL,
+			finished_at {{time}} NOT NULL,
+			offline     BOOLEAN NOT NULL,
+			jobs        INTEGER NOT NULL,
+			evaluations INTEGER NOT NULL
+		)`,
+		`CREATE TABLE run_usage (
+			run_id             TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+			model              TEXT NOT NULL,
+			calls              INTEGER NOT NULL,
+			prompt_tokens      BIGINT NOT NULL,
+			completion_tokens  BIGINT NOT NULL,
+			wall_time_ms       BIGINT NOT NULL,
+			generation_time_ms BIGINT NOT NULL,
+			cost_usd           DOUBLE PRECISION NOT NULL,
+			priced             BOOLEAN NOT NULL,
+			PRIMARY KEY (run_id, model)
+		)`,
+		// run_id is empty for evaluations imported from the JSON index.
+		// details holds the nested parts as JSON: sub-scores, the normalized
+		// job and the skill match.
+		`CREATE TABLE evaluations (
+			id                  {{id}},
+			job_id              TEXT NOT NULL,
+			run_id              TEXT NOT NULL,
+			evaluated_at        {{time}} NOT NULL,
+			title               TEXT NOT NULL,
+			company             TEXT NOT NULL,
+			apply_link          TEXT NOT NULL,
+			model               TEXT NOT NULL,
+			prompt_version      TEXT NOT NULL,
+			score               INTEGER NOT NULL,
+			raw_score           INTEGER NOT NULL,
+			calibrated          BOOLEAN NOT NULL,
+			model_score         INTEGER NOT NULL,
+			samples             INTEGER NOT NULL,
+			spread              DOUBLE PRECISION NOT NULL,
+			min_score           INTEGER NOT NULL,
+			max_score           INTEGER NOT NULL,
+			low_confidence      BOOLEAN NOT NULL,
+			triage_score        INTEGER NOT NULL,
+			triage_only         BOOLEAN NOT NULL,
+			similarity          DOUBLE PRECISION NOT NULL,
+			feedback_adjustment INTEGER NOT NULL,
+			prompt_tokens       BIGINT NOT NULL,
+			completion_tokens   BIGINT NOT NULL,
+			latency_ms          BIGINT NOT NULL,
+			generation_time_ms  BIGINT NOT NULL,
+			cost_usd            DOUBLE PRECISION NOT NULL,
+			format_errors       INTEGER NOT NULL,
+			response            TEXT NOT NULL,
+			details             TEXT NOT NULL
+		)`,
+		`CREATE INDEX evaluations_job_id ON evaluations (job_id)`,
+		`CREATE TABLE feedback (
+			id       {{id}},
+			job_id   TEXT NOT NULL,
+			kind     TEXT NOT NULL,
+			note     TEXT NOT NULL,
+			score    INTEGER NOT NULL,
+			given_at {{time}} NOT NULL
+		)`,
+		`CREATE INDEX feedback_job_id ON feedback (job_id)`,
+	}},
+	{2, "embeddings, applications and salary checks", []string{
+		`ALTER TABLE job_descriptions ADD COLUMN salary_checked BOOLEAN NOT NULL DEFAULT FALSE`,
+		// vector is a JSON array of floats.
+		`CREATE TABLE job_embeddings (
+			job_id TEXT PRIMARY KEY REFERENCES job_descriptions (job_id) ON DELETE CASCADE,
+			model  TEXT NOT NULL,
+			vector TEXT NOT NULL
+		)`,
+		// details holds the notes and status history as JSON.
+		`CREATE TABLE applications (
+			job_id     TEXT PRIMARY KEY REFERENCES job_descriptions (job_id) ON DELETE CASCADE,
+			status     TEXT NOT NULL,
+			contact    TEXT NOT NULL,
+			updated_at {{time}} NOT NULL,
+			details    TEXT NOT NULL
+		)`,
+	}},
+}
+
+// sqlStore is the repository on database/sql.
+type sqlStore struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+// execer is what sqlStore needs from a *sql.DB or *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// openRepository opens the configured database, bringing its schema up to
+// date first. A new database starts from the legacy JSON job index, when
+// there is one, so upgrading keeps the history.
+func openRepository(ctx context.Context) (repository, error) {
+	s, err := openSQLStore(ctx, config.Storage.Database, config.Storage.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.importLegacyIndex(ctx, config.Storage.IndexFile); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// withRepository opens the repository for one piece of work. Commands and
+// API handlers are short-lived, so they don't hold a connection open.
+func withRepository(ctx context.Context, fn func(repository) error) error {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer repo.close()
+	return fn(repo)
+}
+
+func openSQLStore(ctx context.Context, database, dsn string) (*sqlStore, error) {
+	dialect, ok := sqlDialects[database]
+	if !ok {
+		return nil, fmt.Errorf("storage.database must be sqlite or postgres, got %q", database)
+	}
+	if database == dbSQLite {
+		var err error
+		if dsn, err = sqliteDSN(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(dialect.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", database, err)
+	}
+	if database == dbSQLite {
+		db.SetMaxOpenConns(1) // SQLite has a single writer; queue in Go, not on SQLITE_BUSY
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s database: %w", database, redactErr(err))
+	}
+
+	s := &sqlStore{db: db, dialect: dialect}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// sqliteDSN turns a file path into a URI with foreign keys on, a busy
+// timeout for concurrent commands, and a time format that sorts and parses
+// back. URIs given as file:… are used as they are.
+func sqliteDSN(path string) (string, error) {
+	if strings.HasPrefix(path, "file:") {
+		return path, nil
+	}
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+	}
+	return "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite", nil
+}
+
+func (s *sqlStore) close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStore) exec(ctx context.Context, ex execer, query string, args ...any) error {
+	_, err := ex.ExecContext(ctx, s.dialect.rebind(query), args...)
+	return err
+}
+
+func (s *sqlStore) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// inTx runs fn in a transaction, committing only if it succeeds.
+func (s *sqlStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// schemaVersion is the highest migration applied, 0 for a new database.
+func (s *sqlStore) schemaVersion(ctx context.Context) (int, error) {
+	var version sql.NullInt64
+	err := s.db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version)
+	return int(version.Int64), err
+}
+
+// migrate applies every migration newer than the database, each in its own
+// transaction together with its schema_migrations row.
+func (s *sqlStore) migrate(ctx context.Context) error {
+	if err := s.exec(ctx, s.db, s.dialect.types.Replace(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at {{time}} NOT NULL
+	)`)); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	current, err := s.schemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range m.statements {
+				if err := s.exec(ctx, tx, s.dialect.types.Replace(stmt)); err != nil {
+					return err
+				}
+			}
+			return s.exec(ctx, tx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				m.version, m.name, time.Now().UTC())
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+		logStage(stageStore).Info("Applied migration", "version", m.version, "name", m.name)
+	}
+	return nil
+}
+
+func (s *sqlStore) saveListings(ctx context.Context, listings []JobListing, seen time.Time) error {
+	seen = seen.UTC()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, l := range listings {
+			err := s.exec(ctx, tx, `INSERT INTO job_listings (job_id, job_position, job_link, company_name, company_profile,
+				job_location, job_posting_date, profile, first_seen, last_seen)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (job_id) DO UPDATE SET job_position = excluded.job_position, job_link = excluded.job_link,
+				company_name = excluded.company_name, company_profile = excluded.company_profile,
+				job_location = excluded.job_location, job_posting_date = excluded.job_posting_date,
+				profile = excluded.profile, last_seen = excluded.last_seen`,
+				l.JobID, l.JobPosition, l.JobLink, l.CompanyName, l.CompanyProfile,
+				l.JobLocation, l.JobPostingDate, l.Profile, seen, seen)
+			if err != nil {
+				return fmt.Errorf("failed to save listing %s: %w", l.JobID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// saveDescriptions upserts descriptions and replaces their recruiters and
+// related jobs. first_seen only ever moves earlier, so importing old
+// history keeps the original date.
+func (s *sqlStore) saveDescriptions(ctx context.Context, descs []JobDescription, seen time.Time) error {
+	seen = seen.UTC()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, d := range descs {
+			if err := s.saveDescription(ctx, tx, d, seen); err != nil {
+				return fmt.Errorf("failed to save description %s: %w", d.JobID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) saveDescription(ctx context.Context, tx *sql.Tx, d JobDescription, seen time.Time) error {
+	var compensation sql.NullString
+	if d.Compensation != nil {
+		data, err := json.Marshal(d.Compensation)
+		if err != nil {
+			return err
+		}
+		compensation = sql.NullString{String: string(data), Valid: true}
+	}
+	err := s.exec(ctx, tx, `INSERT INTO job_descriptions (job_id, job_position, job_location, company_name,
+		company_linkedin_id, job_posting_time, job_description, seniority_level, employment_type, job_function,
+		industries, job_apply_link, profile, compensation, salary_checked, fetched_at, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (job_id) DO UPDATE SET job_position = excluded.job_position, job_location = excluded.job_location,
+		company_name = excluded.company_name, company_linkedin_id = excluded.company_linkedin_id,
+		job_posting_time = excluded.job_posting_time, job_description = excluded.job_description,
+		seniority_level = excluded.seniority_level, employment_type = excluded.employment_type,
+		job_function = excluded.job_function, industries = excluded.industries,
+		job_apply_link = excluded.job_apply_link, profile = excluded.profile,
+		compensation = excluded.compensation, salary_checked = excluded.salary_checked, fetched_at = excluded.fetched_at,
+		first_seen = CASE WHEN excluded.first_seen < job_descriptions.first_seen THEN excluded.first_seen ELSE job_descriptions.first_seen END,
+		last_seen = CASE WHEN excluded.last_seen > job_descriptions.last_seen THEN excluded.last_seen ELSE job_descriptions.last_seen END`,
+		d.JobID, d.JobPosition, d.JobLocation, d.CompanyName, d.CompanyLinkedInID, d.JobPostingTime,
+		d.JobDescription, d.SeniorityLevel, d.EmploymentType, d.JobFunction, d.Industries, d.JobApplyLink,
+		d.Profile, compensation, d.SalaryChecked, d.FetchedAt.UTC(), seen, seen)
+	if err != nil {
+		return err
+	}
+
+	if err := s.exec(ctx, tx, "DELETE FROM recruiters WHERE job_id = ?", d.JobID); err != nil {
+		return err
+	}
+	for i, r := range d.RecruiterDetails {
+		if err := s.exec(ctx, tx, "INSERT INTO recruiters (job_id, seq, name, title) VALUES (?, ?, ?, ?)",
+			d.JobID, i, r.RecruiterName, r.RecruiterTitle); err != nil {
+			return err
+		}
+	}
+
+	if err := s.exec(ctx, tx, "DELETE FROM related_jobs WHERE job_id = ?", d.JobID); err != nil {
+		return err
+	}
+	for relation, jobs := range map[string][]SimilarJob{"similar": d.SimilarJobs, "people_also_viewed": d.PeopleAlsoViewed} {
+		for i, j := range jobs {
+			if err := s.exec(ctx, tx, `INSERT INTO related_jobs (job_id, relation, seq, job_position, job_company,
+				job_location, job_posting_time, job_link) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				d.JobID, relation, i, j.JobPosition, j.JobCompany, j.JobLocation, j.JobPostingTime, j.JobLink); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// saveEmbedding stores the job's vector, replacing one from another model.
+func (s *sqlStore) saveEmbedding(ctx context.Context, jobID, model string, vector []float64) error {
+	return s.upsertEmbedding(ctx, s.db, jobID, model, vector)
+}
+
+func (s *sqlStore) upsertEmbedding(ctx context.Context, ex execer, jobID, model string, vector []float64) error {
+	data, err := json.Marshal(vector)
+	if err != nil {
+		return err
+	}
+	err = s.exec(ctx, ex, `INSERT INTO job_embeddings (job_id, model, vector) VALUES (?, ?, ?)
+		ON CONFLICT (job_id) DO UPDATE SET model = excluded.model, vector = excluded.vector`,
+		jobID, model, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save embedding of %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// applicationDetails are the lists of an Application, stored as JSON.
+type applicationDetails struct {
+	Notes   []ApplicationNote `json:"notes,omitempty"`
+	History []StatusChange    `json:"history,omitempty"`
+}
+
+func (s *sqlStore) updateApplication(ctx context.Context, jobID string, u applicationUpdate) (*Application, error) {
+	var app *Application
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRowContext(ctx, s.dialect.rebind("SELECT COUNT(*) FROM job_descriptions WHERE job_id = ?"), jobID).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("JobID %s: %w", jobID, errJobNotStored)
+		}
+
+		apps, err := s.loadApplications(ctx, tx, "WHERE job_id = ?"+s.dialect.forUpdate, jobID)
+		if err != nil {
+			return err
+		}
+		app = apps[jobID]
+		if app == nil {
+			app = &Application{Status: StatusNew}
+		}
+		app.apply(u)
+		return s.saveApplication(ctx, tx, jobID, app)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func (s *sqlStore) saveApplication(ctx context.Context, ex execer, jobID string, app *Application) error {
+	details, err := json.Marshal(applicationDetails{Notes: app.Notes, History: app.History})
+	if err != nil {
+		return err
+	}
+	err = s.exec(ctx, ex, `INSERT INTO applications (job_id, status, contact, updated_at, details) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (job_id) DO UPDATE SET status = excluded.status, contact = excluded.contact,
+		updated_at = excluded.updated_at, details = excluded.details`,
+		jobID, string(app.Status), app.Contact, app.UpdatedAt.UTC(), string(details))
+	if err != nil {
+		return fmt.Errorf("failed to save application for %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// querier is what loading needs from a *sql.DB or *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadApplications reads the applications matching where, keyed by job.
+func (s *sqlStore) loadApplications(ctx context.Context, q querier, where string, args ...any) (map[string]*Application, error) {
+	rows, err := q.QueryContext(ctx, s.dialect.rebind("SELECT job_id, status, contact, updated_at, details FROM applications "+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	apps := map[string]*Application{}
+	err = scanRows(rows, func() error {
+		var jobID, details string
+		var app Application
+		if err := rows.Scan(&jobID, &app.Status, &app.Contact, &app.UpdatedAt, &details); err != nil {
+			return err
+		}
+		var d applicationDetails
+		if err := json.Unmarshal([]byte(details), &d); err != nil {
+			return fmt.Errorf("application for %s: bad details: %w", jobID, err)
+		}
+		app.Notes, app.History = d.Notes, d.History
+		apps[jobID] = &app
+		return nil
+	})
+	return apps, err
+}
+
+// evaluationDetails are the nested parts of an Evaluation, stored as JSON.
+type evaluationDetails struct {
+	SubScores []SubScore `json:"sub_scores,omitempty"`
+	Job       Job        `json:"job"`
+	Skills    SkillMatch `json:"skills"`
+	Triaged   bool       `json:"triaged,omitempty"`
+}
+
+func (s *sqlStore) saveEvaluations(ctx context.Context, runID string, evals []Evaluation, at time.Time) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insertEvaluations(ctx, tx, runID, evals, at)
+	})
+}
+
+func (s *sqlStore) insertEvaluations(ctx context.Context, ex execer, runID string, evals []Evaluation, at time.Time) error {
+	at = at.UTC()
+	for _, e := range evals {
+		details, err := json.Marshal(evaluationDetails{SubScores: e.SubScores, Job: e.Job, Skills: e.Skills, Triaged: e.Triaged})
+		if err != nil {
+			return err
+		}
+		err = s.exec(ctx, ex, `INSERT INTO evaluations (job_id, run_id, evaluated_at, title, company, apply_link,
+			model, prompt_version, score, raw_score, calibrated, model_score, samples, spread, min_score, max_score,
+			low_confidence, triage_score, triage_only, similarity, feedback_adjustment, prompt_tokens,
+			completion_tokens, latency_ms, generation_time_ms, cost_usd, format_errors, response, details)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.JobID, runID, at, e.Title, e.Company, e.ApplyLink,
+			e.Model, e.PromptVersion, e.Score, e.RawScore, e.Calibrated, e.ModelScore, e.Samples, e.Spread, e.MinScore, e.MaxScore,
+			e.LowConfidence, e.TriageScore, e.TriageOnly, e.Similarity, e.FeedbackAdjustment, e.PromptTokens,
+			e.CompletionTokens, e.Latency.Milliseconds(), e.GenerationTime.Milliseconds(), e.CostUSD, e.FormatErrors, e.Text, string(details))
+		if err != nil {
+			return fmt.Errorf("failed to save evaluation of %s: %w", e.JobID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) saveRun(ctx context.Context, run runRecord) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insertRun(ctx, tx, run)
+	})
+}
+
+func (s *sqlStore) insertRun(ctx context.Context, ex execer, run runRecord) error {
+	err := s.exec(ctx, ex, "INSERT INTO runs (id, started_at, finished_at, offline, jobs, evaluations) VALUES (?, ?, ?, ?, ?, ?)",
+		run.ID, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.Offline, run.Jobs, run.Evaluations)
+	if err != nil {
+		return fmt.Errorf("failed to save run %s: %w", run.ID, err)
+	}
+	for _, u := range run.Usage {
+		err := s.exec(ctx, ex, `INSERT INTO run_usage (run_id, model, calls, prompt_tokens, completion_tokens,
+			wall_time_ms, generation_time_ms, cost_usd, priced) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run.ID, u.Model, u.Calls, u.PromptTokens, u.CompletionTokens,
+			u.WallTime.Milliseconds(), u.GenerationTime.Milliseconds(), u.CostUSD, u.Priced)
+		if err != nil {
+			return fmt.Errorf("failed to save usage of run %s: %w", run.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) saveFeedback(ctx context.Context, jobID string, fb Feedback) error {
+	return s.insertFeedback(ctx, s.db, jobID, fb)
+}
+
+func (s *sqlStore) insertFeedback(ctx context.Context, ex execer, jobID string, fb Feedback) error {
+	err := s.exec(ctx, ex, "INSERT INTO feedback (job_id, kind, note, score, given_at) VALUES (?, ?, ?, ?, ?)",
+		jobID, string(fb.Kind), fb.Note, fb.Score, fb.At.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save feedback on %s: %w", jobID, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) job(ctx context.Context, jobID string) (storedJob, error) {
+	jobs, err := s.loadJobs(ctx, "WHERE job_id = ?", jobID)
+	if err != nil {
+		return storedJob{}, err
+	}
+	job, ok := jobs[jobID]
+	if !ok {
+		return storedJob{}, fmt.Errorf("JobID %s: %w", jobID, errJobNotStored)
+	}
+
+	rows, err := s.query(ctx, `SELECT job_id, job_position, job_link, company_name, company_profile, job_location,
+		job_posting_date, profile FROM job_listings WHERE job_id = ?`, jobID)
+	if err != nil {
+		return storedJob{}, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var l JobListing
+		if err := rows.Scan(&l.JobID, &l.JobPosition, &l.JobLink, &l.CompanyName, &l.CompanyProfile,
+			&l.JobLocation, &l.JobPostingDate, &l.Profile); err != nil {
+			return storedJob{}, err
+		}
+		job.Listing = &l
+	}
+	return *job, rows.Err()
+}
+
+func (s *sqlStore) jobHistory(ctx context.Context) (map[string]*indexedJob, error) {
+	jobs, err := s.loadJobs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	history := make(map[string]*indexedJob, len(jobs))
+	for id, j := range jobs {
+		history[id] = &indexedJob{
+			Description: j.Description,
+			FirstSeen:   j.FirstSeen,
+			LastSeen:    j.LastSeen,
+			Evaluations: j.Evaluations,
+			Application: j.Application,
+			Feedback:    j.Feedback,
+		}
+	}
+
+	rows, err := s.query(ctx, "SELECT job_id, model, vector FROM job_embeddings")
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var jobID, model, vector string
+		if err := rows.Scan(&jobID, &model, &vector); err != nil {
+			return err
+		}
+		job, ok := history[jobID]
+		if !ok {
+			return nil
+		}
+		if err := json.Unmarshal([]byte(vector), &job.Embedding); err != nil {
+			return fmt.Errorf("embedding of %s: %w", jobID, err)
+		}
+		job.EmbeddingModel = model
+		return nil
+	})
+	return history, err
+}
+
+// loadJobs reads descriptions matching where, with their recruiters,
+// related jobs, evaluations (oldest first), feedback and application. where
+// filters on job_id, which every table shares.
+func (s *sqlStore) loadJobs(ctx context.Context, where string, args ...any) (map[string]*storedJob, error) {
+	jobs := map[string]*storedJob{}
+
+	rows, err := s.query(ctx, `SELECT job_id, job_position, job_location, company_name, company_linkedin_id,
+		job_posting_time, job_description, seniority_level, employment_type, job_function, industries,
+		job_apply_link, profile, compensation, salary_checked, fetched_at, first_seen, last_seen FROM job_descriptions `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var j storedJob
+		var compensation sql.NullString
+		d := &j.Description
+		if err := rows.Scan(&d.JobID, &d.JobPosition, &d.JobLocation, &d.CompanyName, &d.CompanyLinkedInID,
+			&d.JobPostingTime, &d.JobDescription, &d.SeniorityLevel, &d.EmploymentType, &d.JobFunction, &d.Industries,
+			&d.JobApplyLink, &d.Profile, &compensation, &d.SalaryChecked, &d.FetchedAt, &j.FirstSeen, &j.LastSeen); err != nil {
+			return err
+		}
+		if compensation.Valid {
+			d.Compensation = &Compensation{}
+			if err := json.Unmarshal([]byte(compensation.String), d.Compensation); err != nil {
+				return fmt.Errorf("job %s: bad compensation: %w", d.JobID, err)
+			}
+		}
+		jobs[d.JobID] = &j
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.query(ctx, "SELECT job_id, name, title FROM recruiters "+where+" ORDER BY job_id, seq", args...)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var jobID string
+		var r Recruiter
+		if err := rows.Scan(&jobID, &r.RecruiterName, &r.RecruiterTitle); err != nil {
+			return err
+		}
+		if j, ok := jobs[jobID]; ok {
+			j.Description.RecruiterDetails = append(j.Description.RecruiterDetails, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.query(ctx, `SELECT job_id, relation, job_position, job_company, job_location, job_posting_time, job_link
+		FROM related_jobs `+where+" ORDER BY job_id, relation, seq", args...)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var jobID, relation string
+		var sj SimilarJob
+		if err := rows.Scan(&jobID, &relation, &sj.JobPosition, &sj.JobCompany, &sj.JobLocation, &sj.JobPostingTime, &sj.JobLink); err != nil {
+			return err
+		}
+		j, ok := jobs[jobID]
+		if !ok {
+			return nil
+		}
+		if relation == "people_also_viewed" {
+			j.Description.PeopleAlsoViewed = append(j.Description.PeopleAlsoViewed, sj)
+		} else {
+			j.Description.SimilarJobs = append(j.Description.SimilarJobs, sj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.query(ctx, `SELECT job_id, title, company, apply_link, model, prompt_version, score, raw_score,
+		calibrated, model_score, samples, spread, min_score, max_score, low_confidence, triage_score, triage_only,
+		similarity, feedback_adjustment, prompt_tokens, completion_tokens, latency_ms, generation_time_ms, cost_usd,
+		format_errors, response, details FROM evaluations `+where+" ORDER BY evaluated_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var e Evaluation
+		var latencyMS, generationMS int64
+		var details string
+		if err := rows.Scan(&e.JobID, &e.Title, &e.Company, &e.ApplyLink, &e.Model, &e.PromptVersion, &e.Score, &e.RawScore,
+			&e.Calibrated, &e.ModelScore, &e.Samples, &e.Spread, &e.MinScore, &e.MaxScore, &e.LowConfidence, &e.TriageScore, &e.TriageOnly,
+			&e.Similarity, &e.FeedbackAdjustment, &e.PromptTokens, &e.CompletionTokens, &latencyMS, &generationMS, &e.CostUSD,
+			&e.FormatErrors, &e.Text, &details); err != nil {
+			return err
+		}
+		e.Latency = time.Duration(latencyMS) * time.Millisecond
+		e.GenerationTime = time.Duration(generationMS) * time.Millisecond
+		var d evaluationDetails
+		if err := json.Unmarshal([]byte(details), &d); err != nil {
+			return fmt.Errorf("evaluation of %s: bad details: %w", e.JobID, err)
+		}
+		e.SubScores, e.Job, e.Skills, e.Triaged = d.SubScores, d.Job, d.Skills, d.Triaged
+		if j, ok := jobs[e.JobID]; ok {
+			j.Evaluations = append(j.Evaluations, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.query(ctx, "SELECT job_id, kind, note, score, given_at FROM feedback "+where+" ORDER BY given_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var jobID string
+		var fb Feedback
+		if err := rows.Scan(&jobID, &fb.Kind, &fb.Note, &fb.Score, &fb.At); err != nil {
+			return err
+		}
+		if j, ok := jobs[jobID]; ok {
+			j.Feedback = append(j.Feedback, fb)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := s.loadApplications(ctx, s.db, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	for jobID, app := range apps {
+		if j, ok := jobs[jobID]; ok {
+			j.Application = app
+		}
+	}
+	return jobs, nil
+}
+
+// scanRows calls scan for every row and closes rows.
+func scanRows(rows *sql.Rows, scan func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqlStore) runs(ctx context.Context, limit int) ([]runRecord, error) {
+	query := "SELECT id, started_at, finished_at, offline, jobs, evaluations FROM runs ORDER BY started_at DESC"
+	var args []any
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var runs []runRecord
+	byID := map[string]int{}
+	err = scanRows(rows, func() error {
+		var r runRecord
+		if err := rows.Scan(&r.ID, &r.StartedAt, &r.FinishedAt, &r.Offline, &r.Jobs, &r.Evaluations); err != nil {
+			return err
+		}
+		byID[r.ID] = len(runs)
+		runs = append(runs, r)
+		return nil
+	})
+	if err != nil || len(runs) == 0 {
+		return runs, err
+	}
+
+	rows, err = s.query(ctx, `SELECT run_id, model, calls, prompt_tokens, completion_tokens, wall_time_ms,
+		generation_time_ms, cost_usd, priced FROM run_usage ORDER BY run_id, model`)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var runID string
+		var u llmUsage
+		var wallMS, generationMS int64
+		if err := rows.Scan(&runID, &u.Model, &u.Calls, &u.PromptTokens, &u.CompletionTokens, &wallMS,
+			&generationMS, &u.CostUSD, &u.Priced); err != nil {
+			return err
+		}
+		u.WallTime = time.Duration(wallMS) * time.Millisecond
+		u.GenerationTime = time.Duration(generationMS) * time.Millisecond
+		if i, ok := byID[runID]; ok {
+			runs[i].Usage = append(runs[i].Usage, u)
+		}
+		return nil
+	})
+	return runs, err
+}
+
+// storedJobCount is how many descriptions the database holds.
+func (s *sqlStore) storedJobCount(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM job_descriptions").Scan(&n)
+	return n, err
+}
+
+// importJobIndex copies the JSON job index into an empty database: every
+// description, embedding, evaluation, feedback verdict, application and
+// run. It runs in one transaction, so a failed import leaves the database
+// empty and can be retried. Imported evaluations have no run and are dated
+// at the job's last sighting.
+func (s *sqlStore) importJobIndex(ctx context.Context, ix *jobIndex) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM job_descriptions").Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("the database already holds %d jobs; import only into an empty database", n)
+		}
+
+		for _, job := range ix.Jobs {
+			id := job.Description.JobID
+			// Saving at both sightings sets first_seen and last_seen.
+			for _, seen := range []time.Time{job.FirstSeen, job.LastSeen} {
+				if err := s.saveDescription(ctx, tx, job.Description, seen.UTC()); err != nil {
+					return fmt.Errorf("failed to save description %s: %w", id, err)
+				}
+			}
+			if err := s.insertEvaluations(ctx, tx, "", job.Evaluations, job.LastSeen); err != nil {
+				return err
+			}
+			for _, fb := range job.Feedback {
+				if err := s.insertFeedback(ctx, tx, id, fb); err != nil {
+					return err
+				}
+			}
+			if len(job.Embedding) > 0 {
+				if err := s.upsertEmbedding(ctx, tx, id, job.EmbeddingModel, job.Embedding); err != nil {
+					return err
+				}
+			}
+			if job.Application != nil {
+				if err := s.saveApplication(ctx, tx, id, job.Application); err != nil {
+					return err
+				}
+			}
+		}
+		for _, run := range ix.Runs {
+			if err := s.insertRun(ctx, tx, run); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// importLegacyIndex imports the JSON job index at path into a database
+// that holds no jobs yet. Once there are jobs the file is left alone, so
+// it is only ever imported once.
+func (s *sqlStore) importLegacyIndex(ctx context.Context, path string) error {
+	n, err := s.storedJobCount(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	ix, err := readLegacyJobIndex(path)
+	if err != nil {
+		return err
+	}
+	if len(ix.Jobs) == 0 {
+		return nil
+	}
+	if err := s.importJobIndex(ctx, ix); err != nil {
+		return fmt.Errorf("failed to import job index %s: %w", path, err)
+	}
+	logStage(stageStore).Info("Imported the JSON job index", "file", path, "jobs", len(ix.Jobs), "runs", len(ix.Runs))
+	return nil
+}
+
+// runDBCommand manages the database: migrations, importing the JSON job
+// index, and the run history.
+func runDBCommand(args []string) error {
+	usage := errors.New("usage: db <migrate|import|runs> ...")
+	if len(args) == 0 {
+		return usage
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "migrate":
+		s, err := openSQLStore(ctx, config.Storage.Database, config.Storage.DatabaseURL)
+		if err != nil {
+			return err
+		}
+		defer s.close()
+		version, err := s.schemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s database at schema version %d\n", config.Storage.Database, version)
+		return nil
+
+	case "import":
+		fs := flag.NewFlagSet("db import", flag.ContinueOnError)
+		index := fs.String("index", config.Storage.IndexFile, "JSON job index to import")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		ix, err := readLegacyJobIndex(*index)
+		if err != nil {
+			return err
+		}
+		s, err := openSQLStore(ctx, config.Storage.Database, config.Storage.DatabaseURL)
+		if err != nil {
+			return err
+		}
+		defer s.close()
+		if err := s.importJobIndex(ctx, ix); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d jobs and %d runs from %s\n", len(ix.Jobs), len(ix.Runs), *index)
+		return nil
+
+	case "runs":
+		fs := flag.NewFlagSet("db runs", flag.ContinueOnError)
+		limit := fs.Int("n", 20, "number of runs to show")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		return withRepository(ctx, func(repo repository) error {
+			runs, err := repo.runs(ctx, *limit)
+			if err != nil {
+				return err
+			}
+			return printRuns(runs)
+		})
+
+	default:
+		return usage
+	}
+}
+
+func printRuns(runs []runRecord) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTARTED\tDURATION\tMODE\tJOBS\tEVALUATIONS\tLLM CALLS\tTOKENS\tEST. COST")
+	for _, r := range runs {
+		mode := "online"
+		if r.Offline {
+			mode = "offline"
+		}
+		total := usageTotal(r.Usage)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n", r.ID, r.StartedAt.Local().Format("2006-01-02 15:04"),
+			r.FinishedAt.Sub(r.StartedAt).Round(time.Second), mode, r.Jobs, r.Evaluations,
+			total.Calls, total.PromptTokens+total.CompletionTokens, formatCost(total))
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No runs recorded yet.")
+	}
+	return w.Flush()
+}
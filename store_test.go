@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testStores opens a fresh SQLite database, plus Postgres when
+// SCOUT_TEST_POSTGRES_URL points at a disposable database.
+func testStores(t *testing.T) map[string]*sqlStore {
+	t.Helper()
+	ctx := context.Background()
+	stores := map[string]*sqlStore{}
+
+	s, err := openSQLStore(ctx, dbSQLite, filepath.Join(t.TempDir(), "scout.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.close() })
+	stores[dbSQLite] = s
+
+	if dsn := os.Getenv("SCOUT_TEST_POSTGRES_URL"); dsn != "" {
+		pg, err := openSQLStore(ctx, dbPostgres, dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, table := range []string{"applications", "job_embeddings", "feedback", "evaluations", "run_usage", "runs", "related_jobs", "recruiters", "job_descriptions", "job_listings"} {
+			if err := pg.exec(ctx, pg.db, "DELETE FROM "+table); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Cleanup(func() { pg.close() })
+		stores[dbPostgres] = pg
+	}
+	return stores
+}
+
+// seedStore stores jobs the way importing the legacy JSON index does.
+func seedStore(t *testing.T, s *sqlStore, jobs ...*indexedJob) {
+	t.Helper()
+	if err := s.importJobIndex(context.Background(), testJobIndex(jobs...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	q := "SELECT * FROM t WHERE a = ? AND b = ?"
+	if got := sqlDialects[dbSQLite].rebind(q); got != q {
+		t.Errorf("sqlite rebind = %q", got)
+	}
+	if got := sqlDialects[dbPostgres].rebind(q); got != "SELECT * FROM t WHERE a = $1 AND b = $2" {
+		t.Errorf("postgres rebind = %q", got)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "scout.db")
+	for i := 0; i < 2; i++ {
+		s, err := openSQLStore(ctx, dbSQLite, path)
+		if err != nil {
+			t.Fatalf("open %d: %v", i, err)
+		}
+		version, err := s.schemaVersion(ctx)
+		s.close()
+		if err != nil || version != migrations[len(migrations)-1].version {
+			t.Fatalf("open %d: version %d, %v", i, version, err)
+		}
+	}
+
+	if _, err := openSQLStore(ctx, "mysql", path); err == nil {
+		t.Error("expected an error for an unsupported database")
+	}
+}
+
+func storeTestDescription() JobDescription {
+	return JobDescription{
+		JobID:            "4012345678",
+		JobPosition:      "Software Engineer Intern",
+		CompanyName:      "Acme",
+		JobDescription:   "Go and SQL.",
+		Profile:          "swe",
+		FetchedAt:        time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		RecruiterDetails: []Recruiter{{RecruiterName: "Ann", RecruiterTitle: "Recruiter"}, {RecruiterName: "Bo", RecruiterTitle: "Sourcer"}},
+		SimilarJobs:      []SimilarJob{{JobPosition: "Backend Intern", JobCompany: "Initech", JobLink: "https://example.com/1"}},
+		PeopleAlsoViewed: []SimilarJob{{JobPosition: "SRE Intern", JobCompany: "Globex"}},
+		Compensation:     &Compensation{Min: 30, Max: 40, Currency: "USD", Period: periodHour, Source: "llm"},
+	}
+}
+
+func TestSQLStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			desc := storeTestDescription()
+			first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+			later := first.Add(48 * time.Hour)
+
+			if err := s.saveListings(ctx, []JobListing{{JobID: desc.JobID, JobPosition: desc.JobPosition, CompanyName: "Acme", Profile: "swe"}}, first); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.saveDescriptions(ctx, []JobDescription{desc}, later); err != nil {
+				t.Fatal(err)
+			}
+			// Seen again with one recruiter fewer, and an earlier sighting imported.
+			desc.RecruiterDetails = desc.RecruiterDetails[:1]
+			if err := s.saveDescriptions(ctx, []JobDescription{desc}, first); err != nil {
+				t.Fatal(err)
+			}
+
+			eval := Evaluation{
+				JobID: desc.JobID, Title: desc.JobPosition, Company: "Acme", Model: "gemma3:1b", PromptVersion: "evaluate@v2",
+				Score: 72, RawScore: 70, Calibrated: true, ModelScore: 75, Samples: 3, Spread: 2.5, LowConfidence: true,
+				SubScores:    []SubScore{{Key: "skills", Name: "Skills", Weight: 0.5, Score: 80}},
+				Job:          Job{Title: desc.JobPosition, Company: "Acme", Seniority: SeniorityInternship},
+				PromptTokens: 900, CompletionTokens: 120, Latency: 1500 * time.Millisecond, GenerationTime: 2 * time.Second,
+				CostUSD: 0.0012, Text: "Fit Score: 75",
+			}
+			if err := s.saveEvaluations(ctx, "run1", []Evaluation{eval}, later); err != nil {
+				t.Fatal(err)
+			}
+			fb := Feedback{Kind: FeedbackGoodMatch, Note: "spot on", Score: 70, At: later}
+			if err := s.saveFeedback(ctx, desc.JobID, fb); err != nil {
+				t.Fatal(err)
+			}
+
+			job, err := s.job(ctx, desc.JobID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Listing == nil || job.Listing.Profile != "swe" {
+				t.Errorf("listing = %+v", job.Listing)
+			}
+			d := job.Description
+			if d.JobDescription != "Go and SQL." || !d.FetchedAt.Equal(desc.FetchedAt) || d.Compensation == nil || d.Compensation.Max != 40 {
+				t.Errorf("description = %+v", d)
+			}
+			if len(d.RecruiterDetails) != 1 || len(d.SimilarJobs) != 1 || len(d.PeopleAlsoViewed) != 1 || d.PeopleAlsoViewed[0].JobCompany != "Globex" {
+				t.Errorf("recruiters %+v, similar %+v, also viewed %+v", d.RecruiterDetails, d.SimilarJobs, d.PeopleAlsoViewed)
+			}
+			if !job.FirstSeen.Equal(first) || !job.LastSeen.Equal(later) {
+				t.Errorf("seen %v – %v, want %v – %v", job.FirstSeen, job.LastSeen, first, later)
+			}
+			if len(job.Evaluations) != 1 {
+				t.Fatalf("evaluations = %+v", job.Evaluations)
+			}
+			got := job.Evaluations[0]
+			if got.Score != 72 || !got.Calibrated || !got.LowConfidence || got.Spread != 2.5 || got.Latency != eval.Latency ||
+				got.CostUSD != eval.CostUSD || len(got.SubScores) != 1 || got.Job.Seniority != SeniorityInternship {
+				t.Errorf("evaluation = %+v", got)
+			}
+			if len(job.Feedback) != 1 || job.Feedback[0].Kind != FeedbackGoodMatch || !job.Feedback[0].At.Equal(later) {
+				t.Errorf("feedback = %+v", job.Feedback)
+			}
+
+			if _, err := s.job(ctx, "missing"); !errors.Is(err, errJobNotStored) {
+				t.Errorf("missing job: %v", err)
+			}
+
+			history, err := s.jobHistory(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ij := history[desc.JobID]; ij == nil || ij.latestEvaluation().Score != 72 {
+				t.Errorf("history = %+v", history)
+			}
+		})
+	}
+}
+
+func TestSQLStoreRuns(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+			for i, id := range []string{"older", "newer"} {
+				run := runRecord{
+					ID: id, StartedAt: start.Add(time.Duration(i) * time.Hour), FinishedAt: start.Add(time.Duration(i)*time.Hour + 5*time.Minute),
+					Jobs: 10, Evaluations: 4,
+					Usage: []llmUsage{{Model: "gemma3:1b", Calls: 4, PromptTokens: 4000, CompletionTokens: 800, WallTime: time.Minute, GenerationTime: 16 * time.Second}},
+				}
+				if i == 1 {
+					run.Offline = true
+					run.Usage = append(run.Usage, llmUsage{Model: "paid", Calls: 1, CostUSD: 0.25, Priced: true})
+				}
+				if err := s.saveRun(ctx, run); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			runs, err := s.runs(ctx, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs) != 2 || runs[0].ID != "newer" || !runs[0].Offline || len(runs[0].Usage) != 2 || len(runs[1].Usage) != 1 {
+				t.Fatalf("runs = %+v", runs)
+			}
+			if u := runs[1].Usage[0]; u.WallTime != time.Minute || u.tokensPerSecond() != 50 {
+				t.Errorf("usage = %+v", u)
+			}
+			if total := usageTotal(runs[0].Usage); !total.Priced || total.CostUSD != 0.25 {
+				t.Errorf("total = %+v", total)
+			}
+
+			if runs, err := s.runs(ctx, 1); err != nil || len(runs) != 1 {
+				t.Errorf("limited runs = %+v, %v", runs, err)
+			}
+		})
+	}
+}
+
+// legacyTestIndex is a JSON job index as older versions kept it.
+func legacyTestIndex() *jobIndex {
+	first := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	ix := testJobIndex(&indexedJob{
+		Description:    storeTestDescription(),
+		EmbeddingModel: "nomic-embed-text",
+		Embedding:      []float64{0.6, 0.8},
+		FirstSeen:      first,
+		LastSeen:       first.Add(24 * time.Hour),
+		Evaluations:    []Evaluation{{JobID: "4012345678", Score: 64, Model: "gemma3:1b"}},
+		Application: &Application{Status: StatusApplied, Contact: "jane@acme.test", UpdatedAt: first.Add(72 * time.Hour),
+			History: []StatusChange{{Status: StatusApplied, At: first.Add(72 * time.Hour)}}},
+		Feedback: []Feedback{{Kind: FeedbackBadMatch, Score: 64, At: first.Add(48 * time.Hour)}},
+	})
+	ix.Runs = []runRecord{{ID: "r1", StartedAt: first, FinishedAt: first.Add(time.Minute)}}
+	return ix
+}
+
+func TestImportJobIndex(t *testing.T) {
+	ctx := context.Background()
+	ix := legacyTestIndex()
+	first := ix.Jobs["4012345678"].FirstSeen
+
+	s := testStores(t)[dbSQLite]
+
+	// A failure partway through rolls the whole import back, so it can be
+	// retried.
+	broken := legacyTestIndex()
+	broken.Runs = append(broken.Runs, broken.Runs[0])
+	if err := s.importJobIndex(ctx, broken); err == nil {
+		t.Fatal("expected a duplicate run to fail the import")
+	}
+	if n, err := s.storedJobCount(ctx); err != nil || n != 0 {
+		t.Fatalf("after a failed import the database holds %d jobs, %v", n, err)
+	}
+	if runs, _ := s.runs(ctx, 0); len(runs) != 0 {
+		t.Fatalf("after a failed import runs = %+v", runs)
+	}
+
+	if err := s.importJobIndex(ctx, ix); err != nil {
+		t.Fatal(err)
+	}
+	job, err := s.job(ctx, "4012345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.FirstSeen.Equal(first) || !job.LastSeen.Equal(first.Add(24*time.Hour)) || len(job.Evaluations) != 1 || len(job.Feedback) != 1 {
+		t.Errorf("imported job = %+v", job)
+	}
+	if app := job.Application; app == nil || app.Status != StatusApplied || app.Contact != "jane@acme.test" || len(app.History) != 1 {
+		t.Errorf("imported application = %+v", app)
+	}
+	history, err := s.jobHistory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j := history["4012345678"]; j.EmbeddingModel != "nomic-embed-text" || len(j.Embedding) != 2 {
+		t.Errorf("imported embedding = %q %v", j.EmbeddingModel, j.Embedding)
+	}
+	if runs, _ := s.runs(ctx, 0); len(runs) != 1 {
+		t.Errorf("imported runs = %+v", runs)
+	}
+
+	if err := s.importJobIndex(ctx, ix); err == nil {
+		t.Error("expected a second import to be refused")
+	}
+}
+
+func TestOpenRepositoryImportsLegacyIndex(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	index := filepath.Join(dir, "job_index.json")
+	data, err := json.Marshal(legacyTestIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(index, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	withConfig(t, func(c *Config) {
+		c.Storage.Database = dbSQLite
+		c.Storage.DatabaseURL = filepath.Join(dir, "scout.db")
+		c.Storage.IndexFile = index
+	})
+
+	// Opening twice imports once: the second open finds jobs already stored.
+	for i := 0; i < 2; i++ {
+		err := withRepository(ctx, func(repo repository) error {
+			report, err := loadTrendReport(ctx, repo, time.Time{}, time.Time{})
+			if err != nil {
+				return err
+			}
+			if report.Jobs != 1 {
+				t.Errorf("open %d: trends see %d jobs, want 1", i, report.Jobs)
+			}
+			runs, err := repo.runs(ctx, 0)
+			if len(runs) != 1 {
+				t.Errorf("open %d: %d runs, want 1", i, len(runs))
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStoreAPI(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scout.db")
+	withConfig(t, func(c *Config) {
+		c.Storage.Database = dbSQLite
+		c.Storage.DatabaseURL = path
+	})
+	err := withRepository(ctx, func(repo repository) error {
+		if err := repo.saveDescriptions(ctx, []JobDescription{storeTestDescription()}, time.Now()); err != nil {
+			return err
+		}
+		return repo.saveRun(ctx, runRecord{ID: "r1", StartedAt: time.Now(), FinishedAt: time.Now()})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.close()
+	srv := httptest.NewServer(newServeMux(repo))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/api/jobs/4012345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job storedJob
+	json.NewDecoder(res.Body).Decode(&job)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || len(job.Description.RecruiterDetails) != 2 {
+		t.Errorf("GET /api/jobs: %s %+v", res.Status, job)
+	}
+
+	res, err = http.Get(srv.URL + "/api/jobs/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("missing job: %s", res.Status)
+	}
+
+	res, err = http.Get(srv.URL + "/api/runs?n=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []runRecord
+	json.NewDecoder(res.Body).Decode(&runs)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || len(runs) != 1 || runs[0].ID != "r1" {
+		t.Errorf("GET /api/runs: %s %+v", res.Status, runs)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"os"
+	"strings"
+)
+
+// tracer creates every span. Until setupTracing installs a provider it
+// hands out no-op spans, so tracing costs nothing when it is off.
+var tracer = otel.Tracer("github.com/joshnelson00/linkedin-job-scout")
+
+// Tracing exporters for tracing.exporter.
+const (
+	tracingOff    = ""
+	tracingOTLP   = "otlp"
+	tracingStdout = "stdout"
+)
+
+// setupTracing installs the configured exporter and returns a function that
+// flushes pending spans; call it before exiting or the last spans are lost.
+// OTLP goes over HTTP to tracing.endpoint, or to the standard
+// OTEL_EXPORTER_OTLP_* variables when that is empty.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Tracing.Exporter {
+	case tracingOff:
+		return func(context.Context) error { return nil }, nil
+	case tracingStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	case tracingOTLP:
+		var opts []otlptracehttp.Option
+		if endpoint := config.Tracing.Endpoint; endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing.exporter must be otlp or stdout, got %q", config.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", config.Tracing.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(config.Tracing.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(redactErr(err))
+		span.SetStatus(codes.Error, redactSecrets(err.Error()))
+	}
+	span.End()
+}
+
+// redisTracingHook gives every Redis command its own span, named after the
+// command, e.g. "redis GET".
+type redisTracingHook struct{}
+
+func (redisTracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (redisTracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := strings.ToUpper(cmd.Name())
+		ctx, span := tracer.Start(ctx, "redis "+name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "redis"), attribute.String("db.operation", name)))
+		err := next(ctx, cmd)
+		if err == redis.Nil {
+			span.SetAttributes(attribute.Bool("cache.hit", false))
+			endSpan(span, nil) // a miss is not a failure
+			return err
+		}
+		endSpan(span, err)
+		return err
+	}
+}
+
+func (redisTracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracer.Start(ctx, "redis pipeline", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "redis"), attribute.Int("db.commands", len(cmds))))
+		err := next(ctx, cmds)
+		endSpan(span, err)
+		return err
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sync"
+	"testing"
+)
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.InMemoryExporter
+)
+
+// recordSpans routes spans to memory for the rest of the test. The global
+// provider can only be installed once, so every test shares the recorder.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanRecorder)))
+	})
+	spanRecorder.Reset()
+	t.Cleanup(spanRecorder.Reset)
+	return spanRecorder
+}
+
+func spanAttr(s tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range s.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestFetchSpans(t *testing.T) {
+	startDevServer(t, devServerConfig{})
+	spans := recordSpans(t)
+
+	ctx, root := tracer.Start(context.Background(), "run")
+	listings, err := getJobListings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redisDB := offlineRedis(t)
+	redisDB.AddHook(redisTracingHook{})
+	processJobListings(ctx, redisDB, listings[:1])
+	root.End()
+
+	byName := map[string][]tracetest.SpanStub{}
+	for _, s := range spans.GetSpans() {
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+	if n := len(byName["scrapingdog listings"]); n != 2 {
+		t.Errorf("got %d listings spans, want 2", n)
+	}
+	jobs := byName["job"]
+	if len(jobs) != 1 || spanAttr(jobs[0], "job_id").AsString() != listings[0].JobID {
+		t.Fatalf("job spans = %+v", jobs)
+	}
+	rootID := jobs[0].Parent.TraceID()
+	for _, name := range []string{"fetch listings", "scrapingdog job", "redis GET", "redis SET"} {
+		if len(byName[name]) == 0 {
+			t.Errorf("no %q span", name)
+			continue
+		}
+		if byName[name][0].SpanContext.TraceID() != rootID {
+			t.Errorf("%q is not in the run's trace", name)
+		}
+	}
+	for _, s := range byName["scrapingdog job"] {
+		if s.Parent.SpanID() != jobs[0].SpanContext.SpanID() {
+			t.Error("ScrapingDog call is not a child of its job span")
+		}
+		if spanAttr(s, "http.status_code").AsInt64() != 200 {
+			t.Errorf("status = %v", spanAttr(s, "http.status_code"))
+		}
+	}
+}
+
+func TestOllamaSpan(t *testing.T) {
+	startDevServer(t, devServerConfig{})
+	spans := recordSpans(t)
+
+	rubric := defaultRubric
+	if _, err := evaluateSample(context.Background(), evalSettings{rubric: &rubric}, "trace-model", "system", "Software Engineer Intern at Acme"); err != nil {
+		t.Fatal(err)
+	}
+	var chat *tracetest.SpanStub
+	for _, s := range spans.GetSpans() {
+		if s.Name == "ollama chat" {
+			chat = &s
+		}
+	}
+	if chat == nil {
+		t.Fatal("no ollama chat span")
+	}
+	if spanAttr(*chat, "model").AsString() != "trace-model" || spanAttr(*chat, "tokens.completion").AsInt64() <= 0 {
+		t.Errorf("attributes = %v", chat.Attributes)
+	}
+}
+
+func TestSetupTracingRejectsUnknownExporter(t *testing.T) {
+	withConfig(t, func(c *Config) { c.Tracing.Exporter = "zipkin" })
+	if _, err := setupTracing(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+}
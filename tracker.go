@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type ApplicationStatus string
+
+const (
+	StatusNew          ApplicationStatus = "new"
+	StatusInterested   ApplicationStatus = "interested"
+	StatusApplied      ApplicationStatus = "applied"
+	StatusInterviewing ApplicationStatus = "interviewing"
+	StatusOffer        ApplicationStatus = "offer"
+	StatusRejected     ApplicationStatus = "rejected"
+	StatusIgnored      ApplicationStatus = "ignored"
+)
+
+// allApplicationStatuses is the pipeline in order, used for validation and
+// for laying out summaries.
+var allApplicationStatuses = []ApplicationStatus{
+	StatusNew, StatusInterested, StatusApplied, StatusInterviewing, StatusOffer, StatusRejected, StatusIgnored,
+}
+
+func parseApplicationStatus(s string) (ApplicationStatus, error) {
+	status := ApplicationStatus(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range allApplicationStatuses {
+		if status == known {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown status %q (available: %s)", s, joinStatuses(allApplicationStatuses))
+}
+
+func joinStatuses(statuses []ApplicationStatus) string {
+	names := make([]string, len(statuses))
+	for i, s := range statuses {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Application is what we've done about one job since it was emailed.
+type Application struct {
+	Status    ApplicationStatus `json:"status"`
+	Contact   string            `json:"contact,omitempty"`
+	Notes     []ApplicationNote `json:"notes,omitempty"`
+	History   []StatusChange    `json:"history,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type ApplicationNote struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+type StatusChange struct {
+	Status ApplicationStatus `json:"status"`
+	At     time.Time         `json:"at"`
+}
+
+// statusDate is when the application last moved to status, or zero.
+func (a *Application) statusDate(status ApplicationStatus) time.Time {
+	for i := len(a.History) - 1; i >= 0; i-- {
+		if a.History[i].Status == status {
+			return a.History[i].At
+		}
+	}
+	return time.Time{}
+}
+
+// applicationUpdate is a partial change; zero fields are left alone.
+type applicationUpdate struct {
+	Status  ApplicationStatus
+	Contact string
+	Note    string
+	At      time.Time // when the change happened; defaults to now
+}
+
+// handled reports whether the job has been dealt with and should no longer
+// appear in "new jobs" emails.
+func (ij *indexedJob) handled() bool {
+	return ij.Application != nil && ij.Application.Status != StatusNew
+}
+
+func (ij *indexedJob) status() ApplicationStatus {
+	if ij.Application == nil {
+		return StatusNew
+	}
+	return ij.Application.Status
+}
+
+// apply records u on the application.
+func (app *Application) apply(u applicationUpdate) {
+	at := u.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if u.Status != "" && u.Status != app.Status {
+		app.Status = u.Status
+		app.History = append(app.History, StatusChange{Status: u.Status, At: at})
+	}
+	if u.Contact != "" {
+		app.Contact = u.Contact
+	}
+	if u.Note != "" {
+		app.Notes = append(app.Notes, ApplicationNote{At: at, Text: u.Note})
+	}
+	app.UpdatedAt = time.Now()
+}
+
+// trackedJobs returns the jobs matching status (all when empty), most
+// recently updated first and then newest.
+func (ix *jobIndex) trackedJobs(status ApplicationStatus) []*indexedJob {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var jobs []*indexedJob
+	for _, job := range ix.Jobs {
+		if status == "" || job.status() == status {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		ui, uj := updatedAt(jobs[i]), updatedAt(jobs[j])
+		if !ui.Equal(uj) {
+			return ui.After(uj)
+		}
+		return jobs[i].FirstSeen.After(jobs[j].FirstSeen)
+	})
+	return jobs
+}
+
+func updatedAt(job *indexedJob) time.Time {
+	if job.Application == nil {
+		return time.Time{}
+	}
+	return job.Application.UpdatedAt
+}
+
+// pipelineSummary counts jobs in each status, in pipeline order.
+func (ix *jobIndex) pipelineSummary() []countRow {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	counts := map[ApplicationStatus]int{}
+	for _, job := range ix.Jobs {
+		counts[job.status()]++
+	}
+	rows := make([]countRow, len(allApplicationStatuses))
+	for i, s := range allApplicationStatuses {
+		rows[i] = countRow{Name: string(s), Count: counts[s]}
+	}
+	return rows
+}
+
+// filterHandledJobs drops jobs that already have a status beyond "new", so
+// they aren't evaluated or emailed again.
+func filterHandledJobs(ix *jobIndex, descs []JobDescription) []JobDescription {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var kept []JobDescription
+	for _, desc := range descs {
+		if job, ok := ix.Jobs[desc.JobID]; ok && job.handled() {
+			continue
+		}
+		kept = append(kept, desc)
+	}
+	return kept
+}
+
+// updateTrackedJob applies u to the job's stored application. Only stored
+// jobs can be tracked.
+func updateTrackedJob(jobID string, u applicationUpdate) error {
+	ctx := context.Background()
+	return withRepository(ctx, func(repo repository) error {
+		_, err := repo.updateApplication(ctx, jobID, u)
+		return err
+	})
+}
+
+// runTrackCommand manages application statuses from the command line.
+func runTrackCommand(args []string) error {
+	usage := errors.New("usage: track <list|set|note|summary> ...")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("track list", flag.ContinueOnError)
+		statusFlag := fs.String("status", "", "only jobs with this status")
+		all := fs.Bool("all", false, "include untracked jobs")
+		limit := fs.Int("n", 50, "number of jobs to show")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		var status ApplicationStatus
+		if *statusFlag != "" {
+			s, err := parseApplicationStatus(*statusFlag)
+			if err != nil {
+				return err
+			}
+			status = s
+		}
+		ix, err := loadStoredJobIndex(context.Background())
+		if err != nil {
+			return err
+		}
+		return printTrackedJobs(ix.trackedJobs(status), *all || status != "", *limit)
+
+	case "set":
+		fs := flag.NewFlagSet("track set", flag.ContinueOnError)
+		note := fs.String("note", "", "note to add")
+		contact := fs.String("contact", "", "recruiter or referral contact")
+		date := fs.String("date", "", "when the change happened (YYYY-MM-DD), default now")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("usage: track set [-note text] [-contact who] [-date YYYY-MM-DD] <jobID> <status>")
+		}
+		status, err := parseApplicationStatus(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		u := applicationUpdate{Status: status, Note: *note, Contact: *contact}
+		if *date != "" {
+			if u.At, err = time.Parse("2006-01-02", *date); err != nil {
+				return fmt.Errorf("invalid -date: %w", err)
+			}
+		}
+		if err := updateTrackedJob(fs.Arg(0), u); err != nil {
+			return err
+		}
+		fmt.Printf("%s → %s\n", fs.Arg(0), status)
+		return nil
+
+	case "note":
+		if len(args) < 3 {
+			return errors.New("usage: track note <jobID> <text...>")
+		}
+		return updateTrackedJob(args[1], applicationUpdate{Note: strings.Join(args[2:], " ")})
+
+	case "summary":
+		ix, err := loadStoredJobIndex(context.Background())
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "STATUS\tJOBS")
+		for _, row := range ix.pipelineSummary() {
+			fmt.Fprintf(w, "%s\t%d\n", row.Name, row.Count)
+		}
+		return w.Flush()
+
+	default:
+		return usage
+	}
+}
+
+func printTrackedJobs(jobs []*indexedJob, includeUntracked bool, limit int) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tSCORE\tJOB ID\tTITLE\tCOMPANY\tUPDATED\tCONTACT")
+	shown := 0
+	for _, job := range jobs {
+		if shown >= limit {
+			break
+		}
+		if job.Application == nil && !includeUntracked {
+			continue
+		}
+		score := "-"
+		if eval := job.scoredEvaluation(); eval != nil {
+			score = strconv.Itoa(eval.Score)
+		}
+		updated, contact := "-", ""
+		if job.Application != nil {
+			updated = job.Application.UpdatedAt.Format("2006-01-02")
+			contact = job.Application.Contact
+		}
+		d := job.Description
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", job.status(), score, d.JobID, d.JobPosition, d.CompanyName, updated, contact)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Fprintln(w, "No tracked jobs yet; use `track set <jobID> <status>` or `track list -all`.")
+	}
+	return w.Flush()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplicationTracking(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			seedStore(t, s,
+				&indexedJob{Description: JobDescription{JobID: "a"}, FirstSeen: now, LastSeen: now},
+				&indexedJob{Description: JobDescription{JobID: "b"}, FirstSeen: now, LastSeen: now},
+				&indexedJob{Description: JobDescription{JobID: "c"}, FirstSeen: now, LastSeen: now})
+
+			applied := time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC)
+			if _, err := s.updateApplication(ctx, "a", applicationUpdate{Status: StatusApplied, Contact: "jane@acme.test", At: applied}); err != nil {
+				t.Fatal(err)
+			}
+			app, err := s.updateApplication(ctx, "a", applicationUpdate{Note: "Referral from Jane"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if app.Status != StatusApplied || app.Contact != "jane@acme.test" || len(app.Notes) != 1 || !app.statusDate(StatusApplied).Equal(applied) {
+				t.Errorf("unexpected application %+v", app)
+			}
+			if _, err := s.updateApplication(ctx, "b", applicationUpdate{Status: StatusNew, Note: "look later"}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.updateApplication(ctx, "missing", applicationUpdate{Status: StatusOffer}); !errors.Is(err, errJobNotStored) {
+				t.Errorf("tracking a job that isn't stored: %v", err)
+			}
+
+			ix, err := loadJobIndex(ctx, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ix.Jobs["a"].Application; got == nil || got.Status != StatusApplied || len(got.History) != 1 || len(got.Notes) != 1 {
+				t.Errorf("stored application %+v", got)
+			}
+			kept := filterHandledJobs(ix, []JobDescription{{JobID: "a"}, {JobID: "b"}, {JobID: "c"}, {JobID: "fresh"}})
+			var ids []string
+			for _, d := range kept {
+				ids = append(ids, d.JobID)
+			}
+			if strings.Join(ids, ",") != "b,c,fresh" {
+				t.Errorf("filterHandledJobs kept %v, want b,c,fresh", ids)
+			}
+
+			summary := ix.pipelineSummary()
+			if summary[0] != (countRow{Name: "new", Count: 2}) || summary[2] != (countRow{Name: "applied", Count: 1}) {
+				t.Errorf("unexpected summary %+v", summary)
+			}
+		})
+	}
+}
+
+func TestParseApplicationStatus(t *testing.T) {
+	if s, err := parseApplicationStatus(" Interviewing "); err != nil || s != StatusInterviewing {
+		t.Errorf("parseApplicationStatus = %q, %v", s, err)
+	}
+	if _, err := parseApplicationStatus("ghosted"); err == nil {
+		t.Error("expected unknown status to fail")
+	}
+}
+
+func TestTrackerFormUpdatesApplication(t *testing.T) {
+	s := testStores(t)[dbSQLite]
+	seedStore(t, s, &indexedJob{Description: JobDescription{JobID: "42", JobPosition: "Go Intern", CompanyName: "Acme"}, FirstSeen: time.Now(), LastSeen: time.Now()})
+
+	mux := newServeMux(s)
+	form := url.Values{"status": {"interested"}, "note": {"great team"}}
+	req := httptest.NewRequest(http.MethodPost, "/tracker/42", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "http://"+req.Host)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /tracker/42 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tracker?status=interested", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "Go Intern") || !strings.Contains(body, "great team") || !strings.Contains(body, "interested (1)") {
+		t.Errorf("tracker page missing update: %d %s", rec.Code, body)
+	}
+
+	postJSON := func(target, body string) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := postJSON("/api/applications/42", `{"status":"bogus"}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown status, got %d", code)
+	}
+	if code := postJSON("/api/applications/missing", `{"status":"applied"}`); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a job that isn't stored, got %d", code)
+	}
+}
+
+func TestTrackerWritesRejectCrossSiteRequests(t *testing.T) {
+	s := testStores(t)[dbSQLite]
+	seedStore(t, s, &indexedJob{Description: JobDescription{JobID: "42", JobPosition: "Go Intern"}, FirstSeen: time.Now(), LastSeen: time.Now()})
+	mux := newServeMux(s)
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/applications/42", strings.NewReader(`{"status":"applied"}`))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("POST /api/applications/42 as %q = %d, want 415", contentType, rec.Code)
+		}
+	}
+
+	for name, headers := range map[string]map[string]string{
+		"no origin":     {},
+		"other origin":  {"Origin": "https://evil.example"},
+		"null origin":   {"Origin": "null"},
+		"other referer": {"Referer": "https://evil.example/tracker"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/tracker/42", strings.NewReader("status=applied"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: POST /tracker/42 = %d, want 403", name, rec.Code)
+		}
+	}
+
+	job, err := s.job(context.Background(), "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Application != nil {
+		t.Errorf("a refused request changed the application: %+v", job.Application)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/tracker/42", strings.NewReader("status=applied"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", "http://"+req.Host+"/tracker")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("same-site Referer: POST /tracker/42 = %d, want 303", rec.Code)
+	}
+}
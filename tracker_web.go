@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type trackedJobResponse struct {
+	JobID       string       `json:"job_id"`
+	Title       string       `json:"title"`
+	Company     string       `json:"company"`
+	ApplyLink   string       `json:"apply_link"`
+	Score       *int         `json:"score,omitempty"`
+	FirstSeen   time.Time    `json:"first_seen"`
+	Status      string       `json:"status"`
+	Application *Application `json:"application,omitempty"`
+}
+
+type applicationRequest struct {
+	Status  string `json:"status"`
+	Contact string `json:"contact"`
+	Note    string `json:"note"`
+	Date    string `json:"date"` // YYYY-MM-DD, default now
+}
+
+func (r applicationRequest) update() (applicationUpdate, error) {
+	u := applicationUpdate{Contact: strings.TrimSpace(r.Contact), Note: strings.TrimSpace(r.Note)}
+	if r.Status != "" {
+		status, err := parseApplicationStatus(r.Status)
+		if err != nil {
+			return u, err
+		}
+		u.Status = status
+	}
+	if r.Date != "" {
+		at, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			return u, fmt.Errorf("invalid date %q, want YYYY-MM-DD", r.Date)
+		}
+		u.At = at
+	}
+	return u, nil
+}
+
+func newTrackedJobResponse(job *indexedJob) trackedJobResponse {
+	resp := trackedJobResponse{
+		JobID:       job.Description.JobID,
+		Title:       job.Description.JobPosition,
+		Company:     job.Description.CompanyName,
+		ApplyLink:   job.Description.JobApplyLink,
+		FirstSeen:   job.FirstSeen,
+		Status:      string(job.status()),
+		Application: job.Application,
+	}
+	if eval := job.scoredEvaluation(); eval != nil {
+		score := eval.Score
+		resp.Score = &score
+	}
+	return resp
+}
+
+// statusFilter reads the optional ?status= parameter.
+func statusFilter(r *http.Request) (ApplicationStatus, error) {
+	if s := r.URL.Query().Get("status"); s != "" {
+		return parseApplicationStatus(s)
+	}
+	return "", nil
+}
+
+// handleListApplications serves GET /api/applications?status=<status>.
+func (s *apiServer) handleListApplications(w http.ResponseWriter, r *http.Request) {
+	status, err := statusFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ix, err := loadJobIndex(r.Context(), s.repo)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, redactSecrets(err.Error()))
+		return
+	}
+
+	jobs := []trackedJobResponse{}
+	for _, job := range ix.trackedJobs(status) {
+		jobs = append(jobs, newTrackedJobResponse(job))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"summary": ix.pipelineSummary(),
+		"jobs":    jobs,
+	})
+}
+
+// handleUpdateApplication serves POST /api/applications/{id} with a JSON
+// applicationRequest body. The body must be sent as application/json, which
+// a cross-site form can't do without a preflight.
+func (s *apiServer) handleUpdateApplication(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeJSONError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var req applicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	u, err := req.update()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	app, err := s.repo.updateApplication(r.Context(), r.PathValue("id"), u)
+	if err != nil {
+		writeJSONError(w, storeErrorStatus(err), redactSecrets(err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, app)
+}
+
+// handleTrackerForm serves POST /tracker/{id} from the tracker page's forms
+// and redirects back to the page. Posts from other sites are refused.
+func (s *apiServer) handleTrackerForm(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin form post", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	req := applicationRequest{
+		Status:  r.PostFormValue("status"),
+		Contact: r.PostFormValue("contact"),
+		Note:    r.PostFormValue("note"),
+		Date:    r.PostFormValue("date"),
+	}
+	u, err := req.update()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := s.repo.updateApplication(r.Context(), r.PathValue("id"), u); err != nil {
+		http.Error(w, redactSecrets(err.Error()), storeErrorStatus(err))
+		return
+	}
+
+	back := "/tracker"
+	if filter := r.PostFormValue("filter"); filter != "" {
+		back += "?status=" + url.QueryEscape(filter)
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// sameOrigin reports whether a form post came from this server's own pages.
+// Browsers send Origin with every POST; Referer covers the few that don't.
+// A request with neither is refused.
+func sameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+	u, err := url.Parse(source)
+	return source != "" && err == nil && u.Host == r.Host
+}
+
+// handleTrackerPage serves GET /tracker, an editable list of jobs with the
+// pipeline summary on top.
+func (s *apiServer) handleTrackerPage(w http.ResponseWriter, r *http.Request) {
+	status, err := statusFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ix, err := loadJobIndex(r.Context(), s.repo)
+	if err != nil {
+		slog.Error("Failed to load job history", "err", err)
+		http.Error(w, redactSecrets(err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(trackerPageHTML(ix.pipelineSummary(), ix.trackedJobs(status), status)))
+}
+
+func trackerPageHTML(summary []countRow, jobs []*indexedJob, filter ApplicationStatus) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html><html><head><meta charset='UTF-8'><title>Application Tracker</title>")
+	sb.WriteString("<style>body{font-family:sans-serif;padding:20px;} .breakdown{border-collapse:collapse;} .breakdown td,.breakdown th{border:1px solid #ddd;padding:4px 8px;text-align:left;vertical-align:top;} .meta{color:#666;font-size:0.85em;} .pipeline a{display:inline-block;margin-right:10px;padding:6px 10px;border:1px solid #ccc;border-radius:6px;text-decoration:none;color:#000;} .pipeline a.active{background:#e8f0fe;} form{margin:0;}</style>")
+	sb.WriteString("</head><body><h1>Application Tracker</h1><p class='pipeline'>")
+
+	total := 0
+	for _, row := range summary {
+		total += row.Count
+	}
+	sb.WriteString(trackerFilterLink("", fmt.Sprintf("all (%d)", total), filter))
+	for _, row := range summary {
+		sb.WriteString(trackerFilterLink(ApplicationStatus(row.Name), fmt.Sprintf("%s (%d)", row.Name, row.Count), filter))
+	}
+	sb.WriteString("</p>")
+
+	sb.WriteString("<table class='breakdown'><tr><th>Score</th><th>Job</th><th>Company</th><th>First Seen</th><th>Status</th><th>Contact</th><th>Notes</th><th></th></tr>")
+	for _, job := range jobs {
+		d := job.Description
+		title := html.EscapeString(d.JobPosition)
+		if d.JobApplyLink != "" {
+			title = fmt.Sprintf("<a href=\"%s\" target=\"_blank\">%s</a>", html.EscapeString(d.JobApplyLink), title)
+		}
+		score := "–"
+		if eval := job.scoredEvaluation(); eval != nil {
+			score = strconv.Itoa(eval.Score)
+		}
+		contact := ""
+		var notes []string
+		if job.Application != nil {
+			contact = job.Application.Contact
+			for _, n := range job.Application.Notes {
+				notes = append(notes, fmt.Sprintf("<div><span class='meta'>%s</span> %s</div>", n.At.Format("Jan 2"), html.EscapeString(n.Text)))
+			}
+		}
+
+		formID := "f-" + html.EscapeString(d.JobID)
+		if job.scoredEvaluation() != nil && feedbackSignature(d.JobID, FeedbackGoodMatch) != "" {
+			var links []string
+			for _, kind := range allFeedbackKinds {
+				links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(feedbackURL("", d.JobID, kind)), feedbackLabels[kind]))
+			}
+			score += "<div class='meta'>" + strings.Join(links, "<br>") + "</div>"
+		}
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s<div class='meta'>%s</div></td><td>%s</td><td>%s</td>",
+			score, title, html.EscapeString(d.JobID), html.EscapeString(d.CompanyName), job.FirstSeen.Format("2006-01-02")))
+		sb.WriteString(fmt.Sprintf("<td><select name='status' form='%s'>", formID))
+		for _, s := range allApplicationStatuses {
+			selected := ""
+			if s == job.status() {
+				selected = " selected"
+			}
+			sb.WriteString(fmt.Sprintf("<option value='%s'%s>%s</option>", s, selected, s))
+		}
+		sb.WriteString("</select></td>")
+		sb.WriteString(fmt.Sprintf("<td><input name='contact' form='%s' value='%s' size='18'></td>", formID, html.EscapeString(contact)))
+		sb.WriteString(fmt.Sprintf("<td>%s<input name='note' form='%s' placeholder='Add note' size='24'></td>", strings.Join(notes, ""), formID))
+		sb.WriteString(fmt.Sprintf("<td><form id='%s' method='post' action='/tracker/%s'><input type='hidden' name='filter' value='%s'><input type='date' name='date'> <button>Save</button></form></td></tr>",
+			formID, url.PathEscape(d.JobID), html.EscapeString(string(filter))))
+	}
+	sb.WriteString("</table></body></html>")
+	return sb.String()
+}
+
+func trackerFilterLink(status ApplicationStatus, label string, current ApplicationStatus) string {
+	href := "/tracker"
+	if status != "" {
+		href += "?status=" + string(status)
+	}
+	class := ""
+	if status == current {
+		class = " class='active'"
+	}
+	return fmt.Sprintf("<a href='%s'%s>%s</a>", href, class, html.EscapeString(label))
+}
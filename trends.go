@@ -0,0 +1,531 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultProfile = "default"
+
+// trendDay aggregates the jobs posted on one calendar day.
+type trendDay struct {
+	Date     time.Time
+	Postings map[string]int // by search profile
+	Total    int
+	Remote   int
+	Hybrid   int
+	Onsite   int
+	scoreSum float64
+	Scored   int
+}
+
+func (d *trendDay) AverageScore() float64 {
+	if d.Scored == 0 {
+		return 0
+	}
+	return d.scoreSum / float64(d.Scored)
+}
+
+// RemoteShare is the fraction of postings with a known workplace that are remote.
+func (d *trendDay) RemoteShare() float64 {
+	known := d.Remote + d.Hybrid + d.Onsite
+	if known == 0 {
+		return 0
+	}
+	return float64(d.Remote) / float64(known)
+}
+
+type countRow struct {
+	Name  string
+	Count int
+}
+
+// repostStat describes a role (same company and title) posted more than once.
+type repostStat struct {
+	Company    string
+	Title      string
+	Postings   int
+	AvgGapDays float64
+}
+
+type trendReport struct {
+	From             time.Time
+	To               time.Time
+	Jobs             int
+	Profiles         []string
+	Days             []*trendDay
+	Companies        []countRow
+	Locations        []countRow
+	Seniority        []countRow
+	Reposts          []repostStat
+	MedianRepostDays float64
+}
+
+// jobDay is when a stored job was posted, falling back to when it was first
+// fetched if the posting time couldn't be parsed.
+func jobDay(ij *indexedJob, job Job) time.Time {
+	t := job.PostedAt
+	if t.IsZero() {
+		t = ij.FirstSeen
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// buildTrendReport aggregates stored jobs posted in [from, to). A zero to
+// means up to today.
+func buildTrendReport(jobs map[string]*indexedJob, from, to time.Time) trendReport {
+	report := trendReport{From: from, To: to}
+	days := map[time.Time]*trendDay{}
+	profiles := map[string]bool{}
+	companies := map[string]int{}
+	locations := map[string]int{}
+	seniority := map[string]int{}
+	roles := map[string][]time.Time{}
+	roleNames := map[string][2]string{}
+
+	for _, ij := range jobs {
+		job := normalizeJob(ij.Description)
+		day := jobDay(ij, job)
+		if day.Before(from) || (!to.IsZero() && !day.Before(to)) {
+			continue
+		}
+		report.Jobs++
+
+		profile := ij.Description.Profile
+		if profile == "" {
+			profile = defaultProfile
+		}
+		profiles[profile] = true
+
+		td, ok := days[day]
+		if !ok {
+			td = &trendDay{Date: day, Postings: map[string]int{}}
+			days[day] = td
+		}
+		td.Postings[profile]++
+		td.Total++
+		switch job.Location.Workplace {
+		case WorkplaceRemote:
+			td.Remote++
+		case WorkplaceHybrid:
+			td.Hybrid++
+		case WorkplaceOnsite:
+			td.Onsite++
+		}
+		if eval := ij.scoredEvaluation(); eval != nil {
+			td.scoreSum += float64(eval.Score)
+			td.Scored++
+		}
+
+		if job.Company != "" {
+			companies[job.Company]++
+		}
+		if loc := trendLocation(job.Location); loc != "" {
+			locations[loc]++
+		}
+		seniority[string(job.Seniority)]++
+
+		if job.Company != "" && job.Title != "" {
+			key := strings.ToLower(job.Company + "\x00" + job.Title)
+			roles[key] = append(roles[key], day)
+			roleNames[key] = [2]string{job.Company, job.Title}
+		}
+	}
+
+	for p := range profiles {
+		report.Profiles = append(report.Profiles, p)
+	}
+	sort.Strings(report.Profiles)
+	report.Days = fillTrendDays(days)
+	report.Companies = sortedCounts(companies)
+	report.Locations = sortedCounts(locations)
+	report.Seniority = sortedCounts(seniority)
+	report.Reposts, report.MedianRepostDays = repostStats(roles, roleNames)
+	return report
+}
+
+// trendLocation groups by region and country; city-level counts are too
+// sparse to show a trend.
+func trendLocation(loc Location) string {
+	var parts []string
+	if loc.Region != "" {
+		parts = append(parts, loc.Region)
+	}
+	if loc.Country != "" {
+		parts = append(parts, loc.Country)
+	}
+	if len(parts) == 0 && loc.Workplace == WorkplaceRemote {
+		return "Remote"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fillTrendDays returns the days in order, adding empty days between the
+// first and last so charts show gaps rather than hiding them.
+func fillTrendDays(days map[time.Time]*trendDay) []*trendDay {
+	if len(days) == 0 {
+		return nil
+	}
+	var first, last time.Time
+	for d := range days {
+		if first.IsZero() || d.Before(first) {
+			first = d
+		}
+		if d.After(last) {
+			last = d
+		}
+	}
+	var out []*trendDay
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		td, ok := days[d]
+		if !ok {
+			td = &trendDay{Date: d, Postings: map[string]int{}}
+		}
+		out = append(out, td)
+	}
+	return out
+}
+
+func sortedCounts(counts map[string]int) []countRow {
+	rows := make([]countRow, 0, len(counts))
+	for name, n := range counts {
+		rows = append(rows, countRow{Name: name, Count: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// repostStats finds roles posted on more than one day and the average gap
+// between postings, plus the median gap across all reposts.
+func repostStats(roles map[string][]time.Time, names map[string][2]string) ([]repostStat, float64) {
+	var stats []repostStat
+	var gaps []float64
+	for key, dates := range roles {
+		sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+		var roleGaps []float64
+		for i := 1; i < len(dates); i++ {
+			if gap := dates[i].Sub(dates[i-1]).Hours() / 24; gap > 0 {
+				roleGaps = append(roleGaps, gap)
+			}
+		}
+		if len(roleGaps) == 0 {
+			continue
+		}
+		gaps = append(gaps, roleGaps...)
+		stats = append(stats, repostStat{
+			Company:    names[key][0],
+			Title:      names[key][1],
+			Postings:   len(dates),
+			AvgGapDays: mean(roleGaps),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Postings != stats[j].Postings {
+			return stats[i].Postings > stats[j].Postings
+		}
+		return stats[i].Company+stats[i].Title < stats[j].Company+stats[j].Title
+	})
+	return stats, median(gaps)
+}
+
+// trendCSVTables lists the tables the trend report can export.
+var trendCSVTables = []string{"daily", "companies", "locations", "seniority", "reposts"}
+
+// writeTrendCSV writes one table of the report as CSV.
+func writeTrendCSV(w io.Writer, report trendReport, table string) error {
+	cw := csv.NewWriter(w)
+	switch table {
+	case "daily":
+		header := []string{"date", "total"}
+		for _, p := range report.Profiles {
+			header = append(header, "profile:"+p)
+		}
+		header = append(header, "remote", "hybrid", "onsite", "remote_share", "scored", "avg_score")
+		cw.Write(header)
+		for _, d := range report.Days {
+			row := []string{d.Date.Format("2006-01-02"), strconv.Itoa(d.Total)}
+			for _, p := range report.Profiles {
+				row = append(row, strconv.Itoa(d.Postings[p]))
+			}
+			row = append(row,
+				strconv.Itoa(d.Remote), strconv.Itoa(d.Hybrid), strconv.Itoa(d.Onsite),
+				strconv.FormatFloat(d.RemoteShare(), 'f', 3, 64),
+				strconv.Itoa(d.Scored), strconv.FormatFloat(d.AverageScore(), 'f', 1, 64))
+			cw.Write(row)
+		}
+	case "companies", "locations", "seniority":
+		rows, column := report.Companies, "company"
+		if table == "locations" {
+			rows, column = report.Locations, "location"
+		} else if table == "seniority" {
+			rows, column = report.Seniority, "seniority"
+		}
+		cw.Write([]string{column, "postings"})
+		for _, r := range rows {
+			cw.Write([]string{r.Name, strconv.Itoa(r.Count)})
+		}
+	case "reposts":
+		cw.Write([]string{"company", "title", "postings", "avg_gap_days"})
+		for _, r := range report.Reposts {
+			cw.Write([]string{r.Company, r.Title, strconv.Itoa(r.Postings), strconv.FormatFloat(r.AvgGapDays, 'f', 1, 64)})
+		}
+	default:
+		return fmt.Errorf("unknown trend table %q (available: %s)", table, strings.Join(trendCSVTables, ", "))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// trendDashboardHTML renders the report as a standalone page. Charts are
+// inline SVG so the page works offline and as an email attachment.
+func trendDashboardHTML(report trendReport) string {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html><html><head><meta charset='UTF-8'><title>Job Market Trends</title>")
+	sb.WriteString("<style>body{font-family:sans-serif;padding:20px;max-width:1000px;} .meta{color:#666;font-size:0.85em;} .breakdown{border-collapse:collapse;margin-bottom:15px;} .breakdown td,.breakdown th{border:1px solid #ddd;padding:4px 8px;text-align:left;} .legend span{display:inline-block;margin-right:12px;} svg{margin-bottom:20px;}</style>")
+	sb.WriteString("</head><body><h1>Job Market Trends</h1>")
+
+	rangeText := "all history"
+	if !report.From.IsZero() {
+		rangeText = "from " + report.From.Format("2006-01-02")
+	}
+	if !report.To.IsZero() {
+		rangeText += " to " + report.To.Format("2006-01-02")
+	}
+	sb.WriteString(fmt.Sprintf("<p class='meta'>%d jobs, %s</p>", report.Jobs, html.EscapeString(rangeText)))
+	if len(report.Days) == 0 {
+		sb.WriteString("<p>No jobs in this range.</p></body></html>")
+		return sb.String()
+	}
+
+	labels := make([]string, len(report.Days))
+	for i, d := range report.Days {
+		labels[i] = d.Date.Format("Jan 2")
+	}
+
+	var postings []chartSeries
+	for i, p := range report.Profiles {
+		s := chartSeries{Name: p, Color: chartColors[i%len(chartColors)]}
+		for _, d := range report.Days {
+			s.Values = append(s.Values, float64(d.Postings[p]))
+		}
+		postings = append(postings, s)
+	}
+	sb.WriteString("<h2>Postings per Day</h2>")
+	sb.WriteString(svgLineChart(labels, postings, 0))
+
+	remote := chartSeries{Name: "remote share %", Color: chartColors[0]}
+	score := chartSeries{Name: "average fit score", Color: chartColors[1]}
+	for _, d := range report.Days {
+		remote.Values = append(remote.Values, d.RemoteShare()*100)
+		score.Values = append(score.Values, d.AverageScore())
+	}
+	sb.WriteString("<h2>Remote Share and Average Fit Score</h2>")
+	sb.WriteString(svgLineChart(labels, []chartSeries{remote, score}, 100))
+
+	sb.WriteString("<h2>Top Hiring Companies</h2>")
+	sb.WriteString(svgBarChart(report.Companies, 15))
+	sb.WriteString("<h2>Locations</h2>")
+	sb.WriteString(svgBarChart(report.Locations, 15))
+	sb.WriteString("<h2>Seniority Mix</h2>")
+	sb.WriteString(svgBarChart(report.Seniority, 10))
+
+	sb.WriteString("<h2>Reposted Roles</h2>")
+	if len(report.Reposts) == 0 {
+		sb.WriteString("<p class='meta'>No roles were posted more than once.</p>")
+	} else {
+		sb.WriteString(fmt.Sprintf("<p class='meta'>Median time to repost: %.1f days</p>", report.MedianRepostDays))
+		sb.WriteString("<table class='breakdown'><tr><th>Company</th><th>Title</th><th>Postings</th><th>Avg Days Between</th></tr>")
+		for i, r := range report.Reposts {
+			if i >= 20 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%d</td><td>%.1f</td></tr>", html.EscapeString(r.Company), html.EscapeString(r.Title), r.Postings, r.AvgGapDays))
+		}
+		sb.WriteString("</table>")
+	}
+	sb.WriteString("</body></html>")
+	return sb.String()
+}
+
+var chartColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+type chartSeries struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+// svgLineChart draws one line per series. A yMax of 0 scales to the data.
+func svgLineChart(labels []string, series []chartSeries, yMax float64) string {
+	const width, height, pad = 900.0, 260.0, 40.0
+	if yMax == 0 {
+		for _, s := range series {
+			for _, v := range s.Values {
+				yMax = max(yMax, v)
+			}
+		}
+		if yMax == 0 {
+			yMax = 1
+		}
+	}
+	x := func(i int) float64 {
+		if len(labels) < 2 {
+			return pad
+		}
+		return pad + float64(i)*(width-2*pad)/float64(len(labels)-1)
+	}
+	y := func(v float64) float64 { return height - pad - v/yMax*(height-2*pad) }
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg width='%.0f' height='%.0f' xmlns='http://www.w3.org/2000/svg' font-size='10'>", width, height))
+	sb.WriteString(fmt.Sprintf("<line x1='%.0f' y1='%.0f' x2='%.0f' y2='%.0f' stroke='#999'/>", pad, height-pad, width-pad, height-pad))
+	sb.WriteString(fmt.Sprintf("<line x1='%.0f' y1='%.0f' x2='%.0f' y2='%.0f' stroke='#999'/>", pad, pad, pad, height-pad))
+	sb.WriteString(fmt.Sprintf("<text x='2' y='%.0f'>%s</text><text x='2' y='%.0f'>0</text>", pad, strconv.FormatFloat(yMax, 'f', -1, 64), height-pad))
+
+	step := max(1, len(labels)/10)
+	for i := 0; i < len(labels); i += step {
+		sb.WriteString(fmt.Sprintf("<text x='%.1f' y='%.0f' text-anchor='middle'>%s</text>", x(i), height-pad+15, html.EscapeString(labels[i])))
+	}
+	for _, s := range series {
+		var points []string
+		for i, v := range s.Values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(i), y(v)))
+		}
+		sb.WriteString(fmt.Sprintf("<polyline fill='none' stroke='%s' stroke-width='2' points='%s'/>", s.Color, strings.Join(points, " ")))
+	}
+	sb.WriteString("</svg><p class='legend'>")
+	for _, s := range series {
+		sb.WriteString(fmt.Sprintf("<span style='color:%s'>■ %s</span>", s.Color, html.EscapeString(s.Name)))
+	}
+	sb.WriteString("</p>")
+	return sb.String()
+}
+
+// svgBarChart draws the top rows as horizontal bars.
+func svgBarChart(rows []countRow, limit int) string {
+	if len(rows) == 0 {
+		return "<p class='meta'>No data.</p>"
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	const width, label, barHeight = 900.0, 260.0, 20.0
+	maxCount := rows[0].Count
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg width='%.0f' height='%.0f' xmlns='http://www.w3.org/2000/svg' font-size='11'>", width, float64(len(rows))*barHeight+5))
+	for i, r := range rows {
+		y := float64(i) * barHeight
+		w := float64(r.Count) / float64(maxCount) * (width - label - 50)
+		sb.WriteString(fmt.Sprintf("<text x='%.0f' y='%.0f' text-anchor='end'>%s</text>", label-5, y+14, html.EscapeString(r.Name)))
+		sb.WriteString(fmt.Sprintf("<rect x='%.0f' y='%.0f' width='%.1f' height='%.0f' fill='%s'/>", label, y+3, w, barHeight-6, chartColors[0]))
+		sb.WriteString(fmt.Sprintf("<text x='%.1f' y='%.0f'>%d</text>", label+w+5, y+14, r.Count))
+	}
+	sb.WriteString("</svg>")
+	return sb.String()
+}
+
+// loadTrendReport builds the report from the job history in the database.
+func loadTrendReport(ctx context.Context, repo repository, from, to time.Time) (trendReport, error) {
+	jobs, err := repo.jobHistory(ctx)
+	if err != nil {
+		return trendReport{}, err
+	}
+	return buildTrendReport(jobs, from, to), nil
+}
+
+// parseDateRange resolves the -since/-from/-to flags shared by the history
+// reports. -from overrides -since.
+func parseDateRange(since time.Duration, fromFlag, toFlag string) (from, to time.Time, err error) {
+	if since > 0 {
+		from = time.Now().Add(-since)
+	}
+	if fromFlag != "" {
+		if from, err = time.Parse("2006-01-02", fromFlag); err != nil {
+			return from, to, fmt.Errorf("invalid -from date: %w", err)
+		}
+	}
+	if toFlag != "" {
+		if to, err = time.Parse("2006-01-02", toFlag); err != nil {
+			return from, to, fmt.Errorf("invalid -to date: %w", err)
+		}
+	}
+	return from, to, nil
+}
+
+// runTrendsCommand writes the trend dashboard and, optionally, CSV tables.
+func runTrendsCommand(args []string) error {
+	fs := flag.NewFlagSet("trends", flag.ContinueOnError)
+	since := fs.Duration("since", 0, "only jobs posted within this long ago, e.g. 720h")
+	fromFlag := fs.String("from", "", "only jobs posted on or after this date (YYYY-MM-DD)")
+	toFlag := fs.String("to", "", "only jobs posted before this date (YYYY-MM-DD)")
+	out := fs.String("out", "trends.html", "dashboard HTML file to write")
+	csvDir := fs.String("csv", "", "directory to write CSV tables into")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	from, to, err := parseDateRange(*since, *fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+	var report trendReport
+	ctx := context.Background()
+	err = withRepository(ctx, func(repo repository) error {
+		var err error
+		report, err = loadTrendReport(ctx, repo, from, to)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if report.Jobs == 0 {
+		return errors.New("no jobs in the history for that date range")
+	}
+
+	if err := os.WriteFile(*out, []byte(trendDashboardHTML(report)), 0644); err != nil {
+		return err
+	}
+	slog.Info("Wrote trend dashboard", "jobs", report.Jobs, "file", *out)
+
+	if *csvDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(*csvDir, 0755); err != nil {
+		return err
+	}
+	for _, table := range trendCSVTables {
+		path := filepath.Join(*csvDir, table+".csv")
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = writeTrendCSV(f, report, table)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	slog.Info("Wrote trend CSV tables", "tables", len(trendCSVTables), "dir", *csvDir)
+	return nil
+}
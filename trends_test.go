@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTrendReport(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 8, d, 9, 0, 0, 0, time.UTC) }
+	job := func(id, profile, title, company, location string, posted time.Time, score int) *indexedJob {
+		ij := &indexedJob{
+			Description: JobDescription{
+				JobID:          id,
+				Profile:        profile,
+				JobPosition:    title,
+				CompanyName:    company,
+				JobLocation:    location,
+				JobPostingTime: posted.Format("2006-01-02"),
+				SeniorityLevel: "Internship",
+				FetchedAt:      posted,
+			},
+			FirstSeen: posted,
+		}
+		if score > 0 {
+			ij.Evaluations = []Evaluation{{JobID: id, Score: score}}
+		}
+		return ij
+	}
+	jobs := map[string]*indexedJob{
+		"1": job("1", "intern", "Backend Intern", "Acme", "Austin, TX", day(1), 80),
+		"2": job("2", "intern", "Backend Intern", "Acme", "United States (Remote)", day(4), 60),
+		"3": job("3", "", "Data Intern", "Globex", "United States (Remote)", day(4), 0),
+		"4": job("4", "intern", "Old Intern", "Initech", "Austin, TX", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), 0),
+	}
+
+	report := buildTrendReport(jobs, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	if report.Jobs != 3 {
+		t.Fatalf("Jobs = %d, want 3", report.Jobs)
+	}
+	if len(report.Days) != 4 {
+		t.Fatalf("expected 4 days including empty gap days, got %d", len(report.Days))
+	}
+	last := report.Days[3]
+	if last.Postings["intern"] != 1 || last.Postings[defaultProfile] != 1 || last.Remote != 2 || last.RemoteShare() != 1 {
+		t.Errorf("unexpected last day %+v", last)
+	}
+	if last.AverageScore() != 60 || report.Days[1].Total != 0 {
+		t.Errorf("unexpected daily scores/gaps: %v, %d", last.AverageScore(), report.Days[1].Total)
+	}
+	if report.Companies[0] != (countRow{Name: "Acme", Count: 2}) {
+		t.Errorf("top company = %+v", report.Companies[0])
+	}
+	if len(report.Reposts) != 1 || report.Reposts[0].AvgGapDays != 3 || report.MedianRepostDays != 3 {
+		t.Errorf("reposts = %+v, median %v", report.Reposts, report.MedianRepostDays)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTrendCSV(&buf, report, "daily"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "date,total,profile:default,profile:intern,remote,hybrid,onsite,remote_share,scored,avg_score" {
+		t.Errorf("unexpected CSV header %q", lines[0])
+	}
+	if lines[4] != "2025-08-04,2,1,1,2,0,0,1.000,1,60.0" {
+		t.Errorf("unexpected CSV row %q", lines[4])
+	}
+	if err := writeTrendCSV(&buf, report, "bogus"); err == nil {
+		t.Error("expected error for unknown table")
+	}
+
+	page := trendDashboardHTML(report)
+	for _, want := range []string{"<svg", "Acme", "Median time to repost: 3.0 days"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("dashboard missing %q", want)
+		}
+	}
+}
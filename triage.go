@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+const (
+	triagePromptName = "triage"
+
+	triageModeModel     = "model"
+	triageModeEmbedding = "embedding"
+)
+
+var triageScorePattern = regexp.MustCompile(`(?i)Triage Score:\s*(\d+(?:\.\d+)?)`)
+
+// triageSettings enables the cheap first pass, scored either by a small chat
+// model or by resume/job embedding similarity. When neither is configured
+// every job goes straight to the full evaluation.
+type triageSettings struct {
+	Mode      string
+	Model     string
+	Threshold int
+	prompt    *PromptTemplate
+}
+
+func loadTriageConfig(c *Config) (triageSettings, error) {
+	cfg := triageSettings{
+		Mode:      c.Triage.Mode,
+		Model:     c.Triage.Model,
+		Threshold: c.Triage.Threshold,
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = triageModeModel
+	case triageModeModel:
+	case triageModeEmbedding:
+		// Without an embedding model there are no similarities to score.
+		if c.Embed.Model == "" {
+			return cfg, fmt.Errorf("triage.mode=%s requires embed.model", triageModeEmbedding)
+		}
+	default:
+		return cfg, fmt.Errorf("triage.mode must be %q or %q, got %q", triageModeModel, triageModeEmbedding, cfg.Mode)
+	}
+	if cfg.Threshold < 0 || cfg.Threshold > 100 {
+		return cfg, fmt.Errorf("triage.threshold must be from 0 to 100, got %d", cfg.Threshold)
+	}
+	if cfg.Mode == triageModeEmbedding || !cfg.enabled() {
+		return cfg, nil
+	}
+
+	prompt, err := loadPromptTemplate(triagePromptName)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.prompt = prompt
+	return cfg, nil
+}
+
+func (c triageSettings) enabled() bool {
+	return c.Mode == triageModeEmbedding || c.Model != ""
+}
+
+// triageJob asks the small model for a single 0-100 score.
+func triageJob(ctx context.Context, s evalSettings, c triageSettings, job JobDescription) (int, error) {
+	prompt, err := c.prompt.Render(PromptData{
+		Resume:        s.resume,
+		CandidateName: s.candidateName,
+		Rubric:        s.rubric,
+		Job:           newPromptJob(job),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := talkToOllama(ctx, ollamaChatURL(), Request{
+		Model:    c.Model,
+		Stream:   false,
+		Messages: []Message{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error talking to Ollama (%s): %w", c.Model, err)
+	}
+
+	m := triageScorePattern.FindStringSubmatch(cleanResponse(resp))
+	if m == nil {
+		return 0, fmt.Errorf("no triage score in response from %s", c.Model)
+	}
+	f, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	return clampScore(int(math.Round(f))), nil
+}
+
+// triageEmbedding turns a job's resume similarity into a 0-100 triage score.
+func triageEmbedding(similarities map[string]float64, job JobDescription) (int, error) {
+	sim, ok := similarities[job.JobID]
+	if !ok {
+		return 0, fmt.Errorf("no embedding similarity for JobID %s", job.JobID)
+	}
+	return clampScore(int(math.Round(sim * 100))), nil
+}
+
+// triageJobs splits jobs into those worth a full evaluation and placeholder
+// evaluations for the rest. A job whose triage fails is passed through rather
+// than silently dropped.
+func triageJobs(ctx context.Context, s evalSettings, c triageSettings, jobs []JobDescription, similarities map[string]float64) ([]JobDescription, map[string]int, []Evaluation) {
+	ctx, span := tracer.Start(ctx, "triage", trace.WithAttributes(attribute.Int("jobs", len(jobs))))
+	defer span.End()
+
+	var deep []JobDescription
+	var skipped []Evaluation
+	scores := map[string]int{}
+
+	scorer := c.Model
+	promptVersion := ""
+	if c.Mode == triageModeEmbedding {
+		scorer = "embedding similarity"
+	} else {
+		promptVersion = c.prompt.ID()
+	}
+
+	for _, job := range jobs {
+		lg := logStage(stageTriage).With("job_id", job.JobID, "model", scorer)
+		var score int
+		var err error
+		if c.Mode == triageModeEmbedding {
+			score, err = triageEmbedding(similarities, job)
+		} else {
+			score, err = triageJob(ctx, s, c, job)
+		}
+		if err != nil {
+			lg.Warn("Triage failed, sending to full review", "err", err)
+			deep = append(deep, job)
+			continue
+		}
+
+		scores[job.JobID] = score
+		lg.Debug("Triaged job", "score", score, "threshold", c.Threshold)
+		if score >= c.Threshold {
+			deep = append(deep, job)
+			continue
+		}
+		normalized := normalizeJob(job)
+		skipped = append(skipped, Evaluation{
+			JobID:         job.JobID,
+			Title:         job.JobPosition,
+			Company:       job.CompanyName,
+			ApplyLink:     job.JobApplyLink,
+			Model:         scorer,
+			PromptVersion: promptVersion,
+			Score:         score,
+			TriageScore:   score,
+			Triaged:       true,
+			TriageOnly:    true,
+			Job:           normalized,
+			Skills:        matchSkills(normalized.Requirements, s.resumeSkills),
+		})
+	}
+
+	logStage(stageTriage).Info("Triage finished", "model", scorer, "kept", len(deep), "jobs", len(jobs), "threshold", c.Threshold)
+	return deep, scores, skipped
+}
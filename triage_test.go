@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// startTriageServer answers each triage prompt with the reply keyed by the
+// job title in it. A reply of "500" fails the request.
+func startTriageServer(t *testing.T, replies map[string]string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		prompt := req.Messages[len(req.Messages)-1].Content
+		for title, reply := range replies {
+			if !strings.Contains(prompt, "Title: "+title+"\n") {
+				continue
+			}
+			if reply == "500" {
+				http.Error(w, "model crashed", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, chatResponse(req.Model, prompt, reply))
+			return
+		}
+		http.Error(w, "unexpected prompt", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	oldClient := httpClient
+	httpClient = srv.Client()
+	t.Cleanup(func() { httpClient = oldClient })
+	withConfig(t, func(c *Config) { c.Ollama.URL = srv.URL + "/api/chat" })
+}
+
+func testTriageSettings(t *testing.T) triageSettings {
+	t.Helper()
+	prompt, err := loadPromptTemplate(triagePromptName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return triageSettings{Mode: triageModeModel, Model: "triage-model", Threshold: 50, prompt: prompt}
+}
+
+func TestTriageJobScoreParsing(t *testing.T) {
+	startTriageServer(t, map[string]string{
+		"Plain":    "Triage Score: 72/100",
+		"Decimal":  "<think>hmm</think>triage score: 64.6/100",
+		"Clamped":  "Triage Score: 140",
+		"Zero":     "Triage Score: 0/100",
+		"Garbled":  "This looks like a decent fit.",
+		"Crashing": "500",
+	})
+	rubric := defaultRubric
+	s := evalSettings{rubric: &rubric}
+	c := testTriageSettings(t)
+
+	for title, want := range map[string]int{"Plain": 72, "Decimal": 65, "Clamped": 100, "Zero": 0} {
+		got, err := triageJob(context.Background(), s, c, JobDescription{JobID: title, JobPosition: title})
+		if err != nil || got != want {
+			t.Errorf("%s: got %d, %v; want %d", title, got, err, want)
+		}
+	}
+	for _, title := range []string{"Garbled", "Crashing"} {
+		if _, err := triageJob(context.Background(), s, c, JobDescription{JobID: title, JobPosition: title}); err == nil {
+			t.Errorf("%s: expected an error", title)
+		}
+	}
+}
+
+func TestTriageJobsThreshold(t *testing.T) {
+	startTriageServer(t, map[string]string{
+		"Strong":   "Triage Score: 80/100",
+		"Boundary": "Triage Score: 50/100",
+		"Weak":     "Triage Score: 0/100",
+		"Garbled":  "No idea.",
+		"Crashing": "500",
+	})
+	rubric := defaultRubric
+	s := evalSettings{rubric: &rubric}
+	var jobs []JobDescription
+	for _, title := range []string{"Strong", "Boundary", "Weak", "Garbled", "Crashing"} {
+		jobs = append(jobs, JobDescription{JobID: title, JobPosition: title, CompanyName: "Acme"})
+	}
+
+	deep, scores, skipped := triageJobs(context.Background(), s, testTriageSettings(t), jobs, nil)
+
+	var kept []string
+	for _, job := range deep {
+		kept = append(kept, job.JobID)
+	}
+	// At or above the threshold goes on, and so does anything triage
+	// couldn't score.
+	if strings.Join(kept, ",") != "Strong,Boundary,Garbled,Crashing" {
+		t.Errorf("kept %v", kept)
+	}
+	if len(scores) != 3 || scores["Strong"] != 80 || scores["Boundary"] != 50 || scores["Weak"] != 0 {
+		t.Errorf("scores = %v", scores)
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("skipped = %+v", skipped)
+	}
+	weak := skipped[0]
+	if weak.JobID != "Weak" || !weak.TriageOnly || !weak.Triaged || weak.TriageScore != 0 || weak.Model != "triage-model" {
+		t.Errorf("skipped evaluation = %+v", weak)
+	}
+}
+
+func TestTriageJobsEmbedding(t *testing.T) {
+	rubric := defaultRubric
+	c := triageSettings{Mode: triageModeEmbedding, Threshold: 40}
+	jobs := []JobDescription{{JobID: "near"}, {JobID: "far"}, {JobID: "unembedded"}}
+
+	deep, scores, skipped := triageJobs(context.Background(), evalSettings{rubric: &rubric}, c, jobs, map[string]float64{"near": 0.81, "far": 0.2})
+	if len(deep) != 2 || deep[0].JobID != "near" || deep[1].JobID != "unembedded" {
+		t.Errorf("deep = %+v", deep)
+	}
+	if scores["near"] != 81 || scores["far"] != 20 {
+		t.Errorf("scores = %v", scores)
+	}
+	if len(skipped) != 1 || skipped[0].Model != "embedding similarity" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestReportShowsZeroTriageScore(t *testing.T) {
+	rubric := defaultRubric
+	path := filepath.Join(t.TempDir(), "report.html")
+	evals := []Evaluation{
+		{JobID: "1", Title: "Triaged", Score: 70, Triaged: true},
+		{JobID: "2", Title: "Untriaged", Score: 60},
+	}
+	if err := writeHTMLFile(path, evals, nil, &rubric, "score", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "Triage: 0/100"); n != 1 {
+		t.Errorf("expected the triaged job's zero score once, found %d", n)
+	}
+}
+
+func TestLoadTriageConfigEmbeddingNeedsModel(t *testing.T) {
+	c := defaultConfig()
+	c.Triage.Mode = triageModeEmbedding
+	if _, err := loadTriageConfig(c); err == nil || !strings.Contains(err.Error(), "embed.model") {
+		t.Errorf("expected embedding triage without embed.model to be rejected, got %v", err)
+	}
+	if errs := featureConfigErrors(c); len(errs) == 0 {
+		t.Error("expected config validation to report it")
+	}
+
+	c.Embed.Model = "nomic-embed-text"
+	s, err := loadTriageConfig(c)
+	if err != nil || !s.enabled() {
+		t.Errorf("got %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// modelPrice is what a paid provider charges for a model, in USD per
+// million tokens.
+type modelPrice struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+// priceTable maps model names to prices. Models that aren't listed, such as
+// local Ollama models, are treated as free and shown as unpriced.
+type priceTable map[string]modelPrice
+
+// loadPriceTable reads a price table such as
+// {"gpt-4o-mini": {"prompt_per_million": 0.15, "completion_per_million": 0.6}}.
+// A missing file means nothing is priced.
+func loadPriceTable(path string) (priceTable, error) {
+	prices := priceTable{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return prices, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("failed to decode price table %s: %w", path, err)
+	}
+	for model, p := range prices {
+		if p.PromptPerMillion < 0 || p.CompletionPerMillion < 0 {
+			return nil, fmt.Errorf("price table %s: %s has a negative price", path, model)
+		}
+	}
+	return prices, nil
+}
+
+// cost estimates the USD cost of one call, reporting whether the model is
+// in the table at all.
+func (p priceTable) cost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.PromptPerMillion + float64(completionTokens)*price.CompletionPerMillion) / 1e6, true
+}
+
+// llmUsage totals the LLM calls made with one model.
+type llmUsage struct {
+	Model            string        `json:"model"`
+	Calls            int           `json:"calls"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	WallTime         time.Duration `json:"wall_time"`       // request round trips as we saw them
+	GenerationTime   time.Duration `json:"generation_time"` // Ollama's eval_duration: time spent producing completion tokens
+	CostUSD          float64       `json:"cost_usd"`
+	Priced           bool          `json:"priced"`
+}
+
+// tokensPerSecond is the generation speed, the way Ollama reports it.
+func (u llmUsage) tokensPerSecond() float64 {
+	if u.GenerationTime <= 0 {
+		return 0
+	}
+	return float64(u.CompletionTokens) / u.GenerationTime.Seconds()
+}
+
+func (u *llmUsage) add(o llmUsage) {
+	u.Calls += o.Calls
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.WallTime += o.WallTime
+	u.GenerationTime += o.GenerationTime
+	u.CostUSD += o.CostUSD
+	u.Priced = u.Priced || o.Priced
+}
+
+// usageTotal sums usage across models.
+func usageTotal(usage []llmUsage) llmUsage {
+	total := llmUsage{Model: "total"}
+	for _, u := range usage {
+		total.add(u)
+	}
+	return total
+}
+
+// usageTracker accumulates every LLM call in a run, whichever stage made
+// it. talkToOllama feeds it through the context.
+type usageTracker struct {
+	mu      sync.Mutex
+	prices  priceTable
+	byModel map[string]*llmUsage
+}
+
+func newUsageTracker(prices priceTable) *usageTracker {
+	return &usageTracker{prices: prices, byModel: map[string]*llmUsage{}}
+}
+
+func (t *usageTracker) record(model string, resp *Response, wall time.Duration) {
+	if t == nil || resp == nil {
+		return
+	}
+	call := llmUsage{
+		Calls:            1,
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		WallTime:         wall,
+		GenerationTime:   time.Duration(resp.EvalDuration),
+	}
+	call.CostUSD, call.Priced = t.prices.cost(model, resp.PromptEvalCount, resp.EvalCount)
+	if call.Priced {
+		llmCost.WithLabelValues(model).Add(call.CostUSD)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.byModel[model]
+	if !ok {
+		u = &llmUsage{Model: model}
+		t.byModel[model] = u
+	}
+	u.add(call)
+}
+
+// summary lists usage per model, by name.
+func (t *usageTracker) summary() []llmUsage {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]llmUsage, 0, len(t.byModel))
+	for _, u := range t.byModel {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+type usageTrackerKey struct{}
+
+func withUsageTracker(ctx context.Context, t *usageTracker) context.Context {
+	return context.WithValue(ctx, usageTrackerKey{}, t)
+}
+
+// usageFrom returns the run's tracker, or nil outside a run (bench,
+// calibrate), which records nothing.
+func usageFrom(ctx context.Context) *usageTracker {
+	t, _ := ctx.Value(usageTrackerKey{}).(*usageTracker)
+	return t
+}
+
+// runRecord is what the history keeps about each pipeline run.
+type runRecord struct {
+	ID          string     `json:"id"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  time.Time  `json:"finished_at"`
+	Offline     bool       `json:"offline,omitempty"`
+	Jobs        int        `json:"jobs"`
+	Evaluations int        `json:"evaluations"`
+	Usage       []llmUsage `json:"usage,omitempty"`
+}
+
+func (ix *jobIndex) addRun(r runRecord) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.Runs = append(ix.Runs, r)
+}
+
+// usageRows is usage plus a total row when there is more than one model.
+func usageRows(usage []llmUsage) []llmUsage {
+	if len(usage) < 2 {
+		return usage
+	}
+	return append(slices.Clone(usage), usageTotal(usage))
+}
+
+// logUsage logs one line per model and, when there are several, the total.
+func logUsage(usage []llmUsage) {
+	for _, u := range usageRows(usage) {
+		logStage(stageLLM).Info("LLM usage", "model", u.Model, "calls", u.Calls,
+			"prompt_tokens", u.PromptTokens, "completion_tokens", u.CompletionTokens,
+			"wall_ms", u.WallTime.Milliseconds(), "tokens_per_second", math.Round(u.tokensPerSecond()*10)/10,
+			"cost", formatCost(u))
+	}
+}
+
+// formatCost shows sub-cent costs with enough digits to be useful.
+func formatCost(u llmUsage) string {
+	if !u.Priced {
+		return "–"
+	}
+	if u.CostUSD < 0.01 {
+		return fmt.Sprintf("$%.4f", u.CostUSD)
+	}
+	return fmt.Sprintf("$%.2f", u.CostUSD)
+}
+
+// usageFooterHTML is the report footer: one row per model plus a total.
+func usageFooterHTML(usage []llmUsage) string {
+	if len(usage) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<h2>LLM Usage</h2><table class='breakdown usage'><tr><th>Model</th><th>Calls</th><th>Prompt Tokens</th><th>Completion Tokens</th><th>Wall Time</th><th>Tokens/s</th><th>Est. Cost</th></tr>")
+	for _, u := range usageRows(usage) {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td><td>%.1f</td><td>%s</td></tr>",
+			html.EscapeString(u.Model), u.Calls, u.PromptTokens, u.CompletionTokens, u.WallTime.Round(100*time.Millisecond), u.tokensPerSecond(), formatCost(u)))
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPriceTable(t *testing.T) {
+	dir := t.TempDir()
+	prices, err := loadPriceTable(filepath.Join(dir, "missing.json"))
+	if err != nil || len(prices) != 0 {
+		t.Fatalf("missing file: %v, %v", prices, err)
+	}
+
+	path := filepath.Join(dir, "prices.json")
+	os.WriteFile(path, []byte(`{"gpt-4o-mini": {"prompt_per_million": 0.15, "completion_per_million": 0.6}}`), 0644)
+	prices, err = loadPriceTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, ok := prices.cost("gpt-4o-mini", 1_000_000, 500_000)
+	if !ok || math.Abs(cost-0.45) > 1e-9 {
+		t.Errorf("cost = %v, %v; want 0.45, true", cost, ok)
+	}
+	if _, ok := prices.cost("gemma3:1b", 1000, 1000); ok {
+		t.Error("unlisted model should be unpriced")
+	}
+
+	os.WriteFile(path, []byte(`{"m": {"prompt_per_million": -1}}`), 0644)
+	if _, err := loadPriceTable(path); err == nil {
+		t.Error("expected an error for a negative price")
+	}
+}
+
+func TestUsageTracker(t *testing.T) {
+	tracker := newUsageTracker(priceTable{"paid": {PromptPerMillion: 1, CompletionPerMillion: 2}})
+	costBefore := metricValue(t, "scout_llm_cost_usd_total", map[string]string{"model": "paid"})
+
+	tracker.record("paid", &Response{PromptEvalCount: 1000, EvalCount: 100, EvalDuration: int64(2 * time.Second)}, 3*time.Second)
+	tracker.record("paid", &Response{PromptEvalCount: 1000, EvalCount: 100, EvalDuration: int64(2 * time.Second)}, 3*time.Second)
+	tracker.record("local", &Response{PromptEvalCount: 500, EvalCount: 50, EvalDuration: int64(time.Second)}, time.Second)
+	tracker.record("local", nil, time.Second) // failed call
+
+	usage := tracker.summary()
+	if len(usage) != 2 || usage[0].Model != "local" || usage[1].Model != "paid" {
+		t.Fatalf("summary = %+v", usage)
+	}
+	paid := usage[1]
+	if paid.Calls != 2 || paid.PromptTokens != 2000 || paid.CompletionTokens != 200 || paid.WallTime != 6*time.Second {
+		t.Errorf("paid = %+v", paid)
+	}
+	if paid.tokensPerSecond() != 50 {
+		t.Errorf("tokens/s = %v, want 50", paid.tokensPerSecond())
+	}
+	if !paid.Priced || math.Abs(paid.CostUSD-0.0024) > 1e-9 {
+		t.Errorf("paid cost = %v (priced %v), want 0.0024", paid.CostUSD, paid.Priced)
+	}
+	if usage[0].Priced || usage[0].Calls != 1 {
+		t.Errorf("local = %+v", usage[0])
+	}
+	if d := metricValue(t, "scout_llm_cost_usd_total", map[string]string{"model": "paid"}) - costBefore; math.Abs(d-0.0024) > 1e-9 {
+		t.Errorf("cost metric grew by %v, want 0.0024", d)
+	}
+
+	total := usageTotal(usage)
+	if total.Calls != 3 || total.CompletionTokens != 250 || !total.Priced {
+		t.Errorf("total = %+v", total)
+	}
+
+	var none *usageTracker
+	none.record("paid", &Response{}, time.Second)
+	if none.summary() != nil {
+		t.Error("nil tracker should record nothing")
+	}
+}
+
+func TestRunUsageFromContext(t *testing.T) {
+	startDevServer(t, devServerConfig{})
+	tracker := newUsageTracker(priceTable{"usage-model": {PromptPerMillion: 1, CompletionPerMillion: 1}})
+	ctx := withUsageTracker(context.Background(), tracker)
+
+	rubric := defaultRubric
+	settings := evalSettings{rubric: &rubric, prices: tracker.prices}
+	eval, err := evaluateSample(ctx, settings, "usage-model", "system", "Software Engineer Intern at Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := tracker.summary()
+	if len(usage) != 1 || usage[0].Calls != 1 || usage[0].CompletionTokens != eval.CompletionTokens {
+		t.Fatalf("summary = %+v, evaluation tokens %d", usage, eval.CompletionTokens)
+	}
+	if eval.CostUSD <= 0 || math.Abs(eval.CostUSD-usage[0].CostUSD) > 1e-12 {
+		t.Errorf("evaluation cost %v, run cost %v", eval.CostUSD, usage[0].CostUSD)
+	}
+	if eval.GenerationTime != usage[0].GenerationTime || usage[0].tokensPerSecond() != 50 {
+		t.Errorf("generation time %v, tokens/s %v", eval.GenerationTime, usage[0].tokensPerSecond())
+	}
+
+	// Outside a run nothing is tracked.
+	if _, err := evaluateSample(context.Background(), settings, "usage-model", "system", "prompt"); err != nil {
+		t.Fatal(err)
+	}
+	if tracker.summary()[0].Calls != 1 {
+		t.Error("call outside the run was tracked")
+	}
+}
+
+func TestRunRecordPersisted(t *testing.T) {
+	ctx := context.Background()
+	s := testStores(t)[dbSQLite]
+	if err := s.saveRun(ctx, runRecord{ID: "abc", Jobs: 3, Evaluations: 2, Usage: []llmUsage{{Model: "m", Calls: 2, CostUSD: 0.5, Priced: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := s.runs(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ID != "abc" || runs[0].Usage[0].CostUSD != 0.5 {
+		t.Errorf("runs = %+v", runs)
+	}
+}
+
+func TestUsageFooterHTML(t *testing.T) {
+	if usageFooterHTML(nil) != "" {
+		t.Error("no usage should mean no footer")
+	}
+	out := usageFooterHTML([]llmUsage{
+		{Model: "<local>", Calls: 2, PromptTokens: 100, CompletionTokens: 40, GenerationTime: 2 * time.Second},
+		{Model: "paid", Calls: 1, PromptTokens: 10, CompletionTokens: 5, CostUSD: 1.234, Priced: true},
+	})
+	for _, want := range []string{"LLM Usage", "&lt;local&gt;", "<td>total</td><td>3</td><td>110</td><td>45</td>", "<td>20.0</td>", "$1.23", "–"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("footer missing %q:\n%s", want, out)
+		}
+	}
+	if got := formatCost(llmUsage{CostUSD: 0.00123, Priced: true}); got != "$0.0012" {
+		t.Errorf("formatCost = %q", got)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// indexedJob is one stored job description, together with its embedding,
+// evaluations, feedback and application.
+type indexedJob struct {
+	Description    JobDescription `json:"description"`
+	EmbeddingModel string         `json:"embedding_model,omitempty"`
+	Embedding      []float64      `json:"embedding,omitempty"`
+	FirstSeen      time.Time      `json:"first_seen"`
+	LastSeen       time.Time      `json:"last_seen"`
+	Evaluations    []Evaluation   `json:"evaluations,omitempty"`
+	Application    *Application   `json:"application,omitempty"`
+	Feedback       []Feedback     `json:"feedback,omitempty"`
+}
+
+func (j *indexedJob) latestEvaluation() *Evaluation {
+	if len(j.Evaluations) == 0 {
+		return nil
+	}
+	return &j.Evaluations[len(j.Evaluations)-1]
+}
+
+// scoredEvaluation is the latest evaluation if it is a full one. Triage
+// scores come from a cheaper model and aren't on the same scale, so a job
+// last seen only by triage has no score to show or learn from.
+func (j *indexedJob) scoredEvaluation() *Evaluation {
+	if eval := j.latestEvaluation(); eval != nil && !eval.TriageOnly {
+		return eval
+	}
+	return nil
+}
+
+// jobIndex is an in-memory view of the stored jobs, loaded from the
+// repository for the pipeline and the reports. Searches are brute-force
+// cosine similarity, which is plenty for a few thousand postings. Runs is
+// only filled when reading a legacy JSON index.
+type jobIndex struct {
+	mu   sync.Mutex
+	Jobs map[string]*indexedJob `json:"jobs"`
+	Runs []runRecord            `json:"runs,omitempty"`
+}
+
+// searchResult is one search hit: the posting and its latest score, without
+// the embedding or the evaluation history.
+type searchResult struct {
+	JobID      string    `json:"job_id"`
+	Title      string    `json:"title"`
+	Company    string    `json:"company"`
+	Location   string    `json:"location,omitempty"`
+	ApplyLink  string    `json:"apply_link,omitempty"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	Similarity float64   `json:"similarity"`
+	Score      *int      `json:"score,omitempty"` // latest full evaluation score; triage-only scores are left out
+	Model      string    `json:"model,omitempty"`
+}
+
+func newSearchResult(job *indexedJob, similarity float64) searchResult {
+	d := job.Description
+	r := searchResult{
+		JobID:      d.JobID,
+		Title:      d.JobPosition,
+		Company:    d.CompanyName,
+		Location:   d.JobLocation,
+		ApplyLink:  d.JobApplyLink,
+		FirstSeen:  job.FirstSeen,
+		LastSeen:   job.LastSeen,
+		Similarity: similarity,
+	}
+	if eval := job.scoredEvaluation(); eval != nil {
+		score := eval.Score
+		r.Score, r.Model = &score, eval.Model
+	}
+	return r
+}
+
+func newJobIndex() *jobIndex {
+	return &jobIndex{Jobs: map[string]*indexedJob{}}
+}
+
+// loadJobIndex reads every stored job from the repository.
+func loadJobIndex(ctx context.Context, repo repository) (*jobIndex, error) {
+	jobs, err := repo.jobHistory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+	return &jobIndex{Jobs: jobs}, nil
+}
+
+// loadStoredJobIndex opens the repository just to load the view, for
+// commands that only read.
+func loadStoredJobIndex(ctx context.Context) (*jobIndex, error) {
+	var ix *jobIndex
+	err := withRepository(ctx, func(repo repository) error {
+		var err error
+		ix, err = loadJobIndex(ctx, repo)
+		return err
+	})
+	return ix, err
+}
+
+// readLegacyJobIndex reads the JSON file the job history was kept in before
+// the database, for importing. A missing file reads as an empty index.
+func readLegacyJobIndex(path string) (*jobIndex, error) {
+	ix := newJobIndex()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job index %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("failed to decode job index %s: %w", path, err)
+	}
+	if ix.Jobs == nil {
+		ix.Jobs = map[string]*indexedJob{}
+	}
+	return ix, nil
+}
+
+// upsertJob records a fetched description. A nil embedding keeps whatever
+// vector the job already had.
+func (ix *jobIndex) upsertJob(desc JobDescription, model string, embedding []float64, seen time.Time) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	job, ok := ix.Jobs[desc.JobID]
+	if !ok {
+		job = &indexedJob{FirstSeen: seen}
+		ix.Jobs[desc.JobID] = job
+	}
+	job.Description = desc
+	job.LastSeen = seen
+	if embedding != nil {
+		job.EmbeddingModel = model
+		job.Embedding = embedding
+	}
+}
+
+// checkedSalary returns the indexed copy of desc if the salary fallback
+// already answered for the same description text.
+func (ix *jobIndex) checkedSalary(desc JobDescription) *JobDescription {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	job, ok := ix.Jobs[desc.JobID]
+	if !ok || !job.Description.SalaryChecked || job.Description.JobDescription != desc.JobDescription {
+		return nil
+	}
+	known := job.Description
+	return &known
+}
+
+// search returns the jobs most similar to query. Jobs embedded with a
+// different model aren't comparable and are skipped.
+func (ix *jobIndex) search(query []float64, model string, limit int) []searchResult {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var results []searchResult
+	for _, job := range ix.Jobs {
+		if job.EmbeddingModel != model || len(job.Embedding) == 0 {
+			continue
+		}
+		results = append(results, newSearchResult(job, cosineSimilarity(query, job.Embedding)))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// indexJobDescriptions stores freshly fetched descriptions and adds them
+// to the view, embedding them when embed.model is set. Embedding failures
+// only cost the job its place in search results, so they are logged and
+// skipped; storage failures are returned.
+func indexJobDescriptions(ctx context.Context, redisDB *redis.Client, repo repository, ix *jobIndex, descs []JobDescription) error {
+	cfg, err := loadEmbedConfig(config)
+	if err != nil {
+		logStage(stageIndex).Warn("Indexing without embeddings", "err", err)
+	}
+
+	now := time.Now()
+	if err := repo.saveDescriptions(ctx, descs, now); err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		var vec []float64
+		if err == nil && cfg.enabled() {
+			var embedErr error
+			vec, embedErr = getCachedEmbedding(ctx, redisDB, cfg, desc.JobID, embeddingText(desc))
+			if embedErr != nil {
+				logStage(stageIndex).Warn("Indexing job without embedding", "job_id", desc.JobID, "model", cfg.Model, "err", embedErr)
+			}
+		}
+		if vec != nil {
+			if err := repo.saveEmbedding(ctx, desc.JobID, cfg.Model, vec); err != nil {
+				return err
+			}
+		}
+		ix.upsertJob(desc, cfg.Model, vec, now)
+	}
+	logStage(stageIndex).Info("Indexed job descriptions", "jobs", len(descs))
+	return nil
+}
+
+// searchJobIndex embeds a free-text query and searches the stored jobs.
+func searchJobIndex(ctx context.Context, repo repository, query string, limit int) ([]searchResult, error) {
+	cfg, err := requireEmbedConfig()
+	if err != nil {
+		return nil, err
+	}
+	ix, err := loadJobIndex(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := getEmbedding(ctx, cfg, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return ix.search(vec, cfg.Model, limit), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testJobIndex is an in-memory view of jobs, keyed by JobID.
+func testJobIndex(jobs ...*indexedJob) *jobIndex {
+	ix := newJobIndex()
+	for _, job := range jobs {
+		ix.Jobs[job.Description.JobID] = job
+	}
+	return ix
+}
+
+func TestJobIndexSearchAndPersistence(t *testing.T) {
+	ctx := context.Background()
+	s := testStores(t)[dbSQLite]
+
+	now := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+	descs := []JobDescription{
+		{JobID: "rust", JobPosition: "Rust Engineer"},
+		{JobID: "go", JobPosition: "Go Engineer"},
+		{JobID: "other", JobPosition: "Other Model"},
+	}
+	if err := s.saveDescriptions(ctx, descs, now); err != nil {
+		t.Fatal(err)
+	}
+	for id, vec := range map[string][]float64{"rust": {1, 0}, "go": {0.6, 0.8}} {
+		if err := s.saveEmbedding(ctx, id, "m", vec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.saveEmbedding(ctx, "other", "other-model", []float64{1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.saveEvaluations(ctx, "", []Evaluation{{JobID: "rust", Score: 77}}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := loadJobIndex(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := ix.search([]float64{1, 0}, "m", 10)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for model m, got %d", len(results))
+	}
+	if results[0].JobID != "rust" || results[0].Title != "Rust Engineer" {
+		t.Errorf("expected rust first, got %+v", results[0])
+	}
+	if score := results[0].Score; score == nil || *score != 77 {
+		t.Errorf("expected the stored evaluation, got %v", score)
+	}
+	if results[1].Score != nil {
+		t.Errorf("unevaluated job should have no score, got %d", *results[1].Score)
+	}
+
+	// Re-seeing a job without an embedding keeps its vector and first-seen date.
+	later := now.Add(24 * time.Hour)
+	ix.upsertJob(JobDescription{JobID: "rust", JobPosition: "Rust Engineer II"}, "", nil, later)
+	job := ix.Jobs["rust"]
+	if len(job.Embedding) != 2 || !job.FirstSeen.Equal(now) || !job.LastSeen.Equal(later) {
+		t.Errorf("unexpected job after re-upsert: %+v", job)
+	}
+}
+
+func TestTriageOnlyScoresAreLeftOut(t *testing.T) {
+	withConfig(t, func(c *Config) { c.Feedback.MinSamples = 1 })
+	job := &indexedJob{
+		Description: JobDescription{JobID: "t"},
+		Evaluations: []Evaluation{{JobID: "t", Score: 80}, {JobID: "t", Score: 20, TriageOnly: true}},
+		Feedback:    []Feedback{{Kind: FeedbackTooLow, Score: 20}},
+	}
+	if r := newSearchResult(job, 1); r.Score != nil {
+		t.Errorf("search result score = %d, want none", *r.Score)
+	}
+	if r := newTrackedJobResponse(job); r.Score != nil {
+		t.Errorf("tracker score = %d, want none", *r.Score)
+	}
+	if page := trackerPageHTML(nil, []*indexedJob{job}, ""); strings.Contains(page, "<td>20</td>") {
+		t.Error("tracker page shows the triage score")
+	}
+	rubric := &Rubric{Criteria: []Criterion{{Key: "skills", Name: "Skills", Weight: 100}}}
+	if m := fitFeedbackModel(testJobIndex(job), rubric); m != nil {
+		t.Errorf("feedback model trained on a triage score: %+v", m)
+	}
+}